@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/lucckkas/patternfinder/internal/aggregate"
+	"github.com/lucckkas/patternfinder/internal/gaps"
+	"github.com/lucckkas/patternfinder/internal/lcs"
+	"github.com/lucckkas/patternfinder/internal/utils"
+)
+
+// runConsensus implementa -mode consensus: busca los patrones más largos
+// presentes en al menos minFrac de las secuencias (subsecuencia común k-way)
+// y los escribe con los rangos de gaps observados en todos sus miembros (un
+// patrón por rango contiguo, ver aggregate.FormatPatternWithSymbols).
+// Devuelve las estadísticas para el CSV.
+func runConsensus(output io.Writer, sequences, seqIDs []string, minFrac float64, top int, classes *lcs.Classes) map[string]*gaps.PatternStat {
+	translated := make([]string, len(sequences))
+	upper := make([]string, len(sequences))
+	for i, s := range sequences {
+		translated[i] = classes.Translate(s)
+		upper[i] = utils.UpperOnly(translated[i])
+	}
+
+	var sym aggregate.SymbolFunc
+	if classes != nil {
+		sym = classes.Symbol
+	}
+
+	motifs := lcs.Consensus(upper, minFrac, top, lcs.DefaultKWayCells)
+	stats := make(map[string]*gaps.PatternStat)
+	if len(motifs) == 0 {
+		fmt.Fprintf(output, "No se encontraron patrones presentes en al menos %.2f%% de las secuencias\n", minFrac*100)
+		return stats
+	}
+
+	for k, m := range motifs {
+		// Gaps: unión de los valores viables en cada secuencia miembro
+		sets := make([][]map[int]struct{}, 0, len(m.Members))
+		for _, i := range m.Members {
+			if s, ok := gaps.AllGapValuesDistanceTotalViable(translated[i], m.Pattern); ok {
+				sets = append(sets, s)
+			}
+		}
+		// Un patrón PROSITE por rango contiguo de valores de cada gap
+		union := aggregate.UnionSets(sets...)
+		patterns := aggregate.FormatPatternWithSymbols(m.Pattern, union, sym)
+
+		method := "exacta"
+		if !m.Exact {
+			method = "heurística"
+		}
+		ids := make([]string, len(m.Members))
+		for j, i := range m.Members {
+			ids[j] = seqIDs[i]
+		}
+
+		fmt.Fprintf(output, "========================================\n")
+		fmt.Fprintf(output, "Consenso %d: %s\n", k+1, strings.Join(patterns, " o "))
+		fmt.Fprintf(output, "========================================\n")
+		fmt.Fprintf(output, "Soporte: %d/%d secuencias (%.2f%%) | subsecuencia común %s\n",
+			len(m.Members), len(sequences), float64(len(m.Members))/float64(len(sequences))*100, method)
+		fmt.Fprintf(output, "Secuencias: %s\n\n", strings.Join(ids, ", "))
+
+		members := make(map[int]bool, len(m.Members))
+		for _, i := range m.Members {
+			members[i+1] = true
+		}
+		for _, pattern := range patterns {
+			stats[pattern] = &gaps.PatternStat{
+				Pattern:         pattern,
+				UppercaseCount:  len(m.Pattern),
+				SequenceIndices: members,
+			}
+		}
+	}
+	return stats
+}
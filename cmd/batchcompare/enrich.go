@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/lucckkas/patternfinder/internal/enrich"
+	"github.com/lucckkas/patternfinder/internal/gaps"
+)
+
+// fdrLevel es el umbral de q-valor del resumen de enriquecimiento.
+const fdrLevel = 0.05
+
+// enrichStats calcula la significancia de los patrones que van al CSV (ver
+// enrich.Analyze). Devuelve nil si no se pidió (-perm 0) o si falla; en ese
+// caso el CSV se genera sin las columnas de significancia.
+func enrichStats(ctx context.Context, sequences []string, stats map[string]*gaps.PatternStat, opts enrich.Options) map[string]enrich.Result {
+	if opts.Permutations <= 0 || len(stats) == 0 {
+		return nil
+	}
+	fmt.Printf("Enriquecimiento: %d patrones, %d permutaciones (modelo %s, semilla %d)...\n",
+		len(stats), opts.Permutations, opts.Null, opts.Seed)
+	results, err := enrich.Stats(ctx, sequences, stats, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error en el enriquecimiento: %v\n", err)
+		return nil
+	}
+	significant := 0
+	for _, r := range results {
+		if r.QValue < fdrLevel {
+			significant++
+		}
+	}
+	fmt.Printf("Patrones significativos (q < %.2f): %d de %d\n", fdrLevel, significant, len(results))
+	return results
+}
+
+// topDiscriminative es la cantidad de patrones del resumen de -neg.
+const topDiscriminative = 10
+
+// discriminateStats busca los patrones que van al CSV en las secuencias
+// positivas y en las negativas (ver enrich.Discriminate) e imprime los más
+// discriminativos. Devuelve nil si no hay conjunto negativo o si falla.
+func discriminateStats(positives, negatives []string, stats map[string]*gaps.PatternStat) map[string]enrich.Contrast {
+	if len(negatives) == 0 || len(stats) == 0 {
+		return nil
+	}
+	patterns := make([]string, 0, len(stats))
+	for p := range stats {
+		patterns = append(patterns, p)
+	}
+	sort.Strings(patterns)
+	fmt.Printf("Contraste: %d patrones en %d positivas y %d negativas...\n",
+		len(patterns), len(positives), len(negatives))
+	ranking, err := enrich.Discriminate(positives, negatives, patterns, gaps.MatchDefault)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error en el contraste: %v\n", err)
+		return nil
+	}
+	fmt.Printf("Patrones más discriminativos:\n")
+	for _, c := range ranking {
+		if c.Rank > topDiscriminative {
+			break
+		}
+		fmt.Printf("  %2d. %-30s pos %d/%d  neg %d/%d  dif %.3f  OR %.3g  p %.3g\n",
+			c.Rank, c.Pattern, c.Pos, c.PosTotal, c.Neg, c.NegTotal, c.SupportDiff, c.OddsRatio, c.PValue)
+	}
+	out := make(map[string]enrich.Contrast, len(ranking))
+	for _, c := range ranking {
+		out[c.Pattern] = c
+	}
+	return out
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func numberedJobs(n int) []Job {
+	jobs := make([]Job, n)
+	for k := range jobs {
+		jobs[k] = Job{Index: k + 1, SeqI: 1, SeqJ: k + 2}
+	}
+	return jobs
+}
+
+func TestExecuteParallelOrder(t *testing.T) {
+	const n, workers = 60, 3
+	jobs := numberedJobs(n)
+	window := int64(workers * reorderWindowPerWorker)
+
+	var dispatched, ordered atomic.Int64
+	run := func(_ context.Context, job Job) ComparisonResult {
+		// Un trabajo nunca se despacha más allá de la ventana
+		if d := dispatched.Add(1); d > ordered.Load()+window {
+			t.Errorf("job %d dispatched with %d written: window %d exceeded", job.Index, ordered.Load(), window)
+		}
+		// Los primeros de cada tanda tardan más: terminan fuera de orden
+		time.Sleep(time.Duration((n-job.Index)%7) * time.Millisecond)
+		return ComparisonResult{Index: job.Index, SeqI: job.SeqI, SeqJ: job.SeqJ}
+	}
+	var done, got []int
+	onDone := func(r ComparisonResult) { done = append(done, r.Index) }
+	onOrdered := func(r ComparisonResult) {
+		got = append(got, r.Index)
+		ordered.Add(1)
+	}
+	executeParallel(context.Background(), jobs, workers, run, onDone, onOrdered)
+
+	if len(got) != n || len(done) != n {
+		t.Fatalf("ordered %d and done %d results, want %d", len(got), len(done), n)
+	}
+	for k, idx := range got {
+		if idx != k+1 {
+			t.Fatalf("result %d written at position %d: %v", idx, k+1, got)
+		}
+	}
+}
+
+func TestExecuteInterrupted(t *testing.T) {
+	const stopAt = 5
+	for _, parallel := range []bool{false, true} {
+		ctx, cancel := context.WithCancel(context.Background())
+		run := func(ctx context.Context, job Job) ComparisonResult {
+			r := ComparisonResult{Index: job.Index}
+			if job.Index == stopAt {
+				// Ctrl-C a mitad de esta comparación
+				cancel()
+				r.Interrupted = true
+			}
+			return r
+		}
+		var done, got []int
+		onDone := func(r ComparisonResult) { done = append(done, r.Index) }
+		onOrdered := func(r ComparisonResult) { got = append(got, r.Index) }
+		if parallel {
+			executeParallel(ctx, numberedJobs(20), 2, run, onDone, onOrdered)
+		} else {
+			executeSequential(ctx, numberedJobs(20), run, onDone, onOrdered)
+		}
+		cancel()
+
+		for _, idx := range done {
+			if idx == stopAt {
+				t.Errorf("parallel=%v: the interrupted job reached onDone (journal)", parallel)
+			}
+		}
+		// La salida en orden se detiene justo antes del interrumpido
+		if len(got) != stopAt-1 || got[len(got)-1] != stopAt-1 {
+			t.Errorf("parallel=%v: written %v, want 1..%d", parallel, got, stopAt-1)
+		}
+	}
+}
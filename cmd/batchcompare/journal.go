@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lucckkas/patternfinder/internal/compare"
+)
+
+// journalEntry es una línea del journal (JSON Lines): el resultado de una
+// comparación terminada. Hash identifica el contenido del par y las opciones
+// que afectan el resultado, así al reanudar solo se reutilizan resultados de
+// las mismas secuencias comparadas de la misma forma.
+type journalEntry struct {
+	SeqI      int             `json:"seq_i"`
+	SeqJ      int             `json:"seq_j"`
+	Hash      string          `json:"hash"`
+	Skipped   bool            `json:"skipped,omitempty"`
+	LCSLength int             `json:"lcs_length,omitempty"`
+	Text      string          `json:"text,omitempty"`
+	Report    *compare.Report `json:"report,omitempty"`
+}
+
+// journalSyncInterval es cada cuánto se sincroniza el journal con el disco:
+// un corte del sistema pierde a lo sumo los resultados de ese lapso.
+const journalSyncInterval = 2 * time.Second
+
+// pairKey identifica un par por sus índices (base 1).
+type pairKey struct{ i, j int }
+
+// Journal es un archivo de solo-agregado con una línea por comparación
+// terminada. Cada línea se escribe completa con una sola llamada, así un
+// corte deja a lo sumo una última línea incompleta que se descarta al leer.
+type Journal struct {
+	mu     sync.Mutex
+	f      *os.File
+	path   string
+	synced time.Time // última sincronización con el disco
+}
+
+// pairHash resume el contenido de un par y la huella de opciones.
+func pairHash(seq1, seq2, fingerprint string) string {
+	h := sha256.New()
+	io.WriteString(h, seq1)
+	h.Write([]byte{0})
+	io.WriteString(h, seq2)
+	h.Write([]byte{0})
+	io.WriteString(h, fingerprint)
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// openJournal abre el journal en path. Con resume se leen las entradas
+// existentes (las líneas dañadas se ignoran) y se siguen agregando al final;
+// sin resume el archivo se trunca.
+func openJournal(path string, resume bool) (*Journal, map[pairKey]journalEntry, error) {
+	done := make(map[pairKey]journalEntry)
+	if !resume {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &Journal{f: f, path: path, synced: time.Now()}, done, nil
+	}
+
+	endsWithNewline := true
+	if data, err := os.Open(path); err == nil {
+		reader := bufio.NewReader(data)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				endsWithNewline = line[len(line)-1] == '\n'
+				var e journalEntry
+				if json.Unmarshal(line, &e) == nil && e.Hash != "" {
+					done[pairKey{e.SeqI, e.SeqJ}] = e
+				}
+			}
+			if err != nil {
+				break
+			}
+		}
+		data.Close()
+	} else if !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Si el proceso murió a mitad de una línea, empezar en una línea nueva
+	if !endsWithNewline {
+		if _, err := f.Write([]byte{'\n'}); err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+	}
+	return &Journal{f: f, path: path, synced: time.Now()}, done, nil
+}
+
+// Append agrega el resultado de una comparación y sincroniza el archivo si
+// pasó journalSyncInterval desde la última vez.
+func (j *Journal) Append(r ComparisonResult) error {
+	line, err := json.Marshal(journalEntry{
+		SeqI:      r.SeqI,
+		SeqJ:      r.SeqJ,
+		Hash:      r.Hash,
+		Skipped:   r.Skipped,
+		LCSLength: r.LCSLength,
+		Text:      r.Text,
+		Report:    r.Report,
+	})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.f.Write(line); err != nil {
+		return err
+	}
+	if time.Since(j.synced) >= journalSyncInterval {
+		j.synced = time.Now()
+		return j.f.Sync()
+	}
+	return nil
+}
+
+// Close sincroniza y cierra el journal.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.f.Sync(); err != nil {
+		j.f.Close()
+		return err
+	}
+	return j.f.Close()
+}
+
+// restore convierte una entrada del journal en el resultado del trabajo.
+func (e journalEntry) restore(job Job) ComparisonResult {
+	return ComparisonResult{
+		Index:     job.Index,
+		SeqI:      job.SeqI,
+		SeqJ:      job.SeqJ,
+		Hash:      e.Hash,
+		Skipped:   e.Skipped,
+		LCSLength: e.LCSLength,
+		Text:      e.Text,
+		Report:    e.Report,
+		Restored:  true,
+	}
+}
+
+// restoreJobs marca los trabajos cuyo par está en done con el mismo hash
+// (mismo contenido y mismas opciones) y devuelve cuántos se recuperaron.
+func restoreJobs(jobs []Job, done map[pairKey]journalEntry) int {
+	recovered := 0
+	for k, job := range jobs {
+		if e, ok := done[pairKey{job.SeqI, job.SeqJ}]; ok && e.Hash == job.Hash {
+			restored := e.restore(job)
+			jobs[k].Restored = &restored
+			recovered++
+		}
+	}
+	return recovered
+}
+
+// journalFingerprint resume lo que cambia el resultado de un par: la versión
+// de los resultados y la huella de opts (las mismas que la clave del caché),
+// más -min-lcs y -timeout, que solo usa batchcompare.
+func journalFingerprint(opts compare.Options, minLCS int) string {
+	return fmt.Sprintf("%s;%s;min-lcs=%d;timeout=%v", compare.ResultVersion, opts.Fingerprint(), minLCS, opts.Timeout)
+}
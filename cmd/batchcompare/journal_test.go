@@ -0,0 +1,142 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lucckkas/patternfinder/internal/compare"
+	"github.com/lucckkas/patternfinder/internal/gaps"
+)
+
+// journalJobs arma un trabajo por par de seqs con la huella de opts.
+func journalJobs(seqs []string, opts compare.Options) []Job {
+	fingerprint := journalFingerprint(opts, 0)
+	var jobs []Job
+	for i := range seqs {
+		for j := i + 1; j < len(seqs); j++ {
+			jobs = append(jobs, Job{
+				Index: len(jobs) + 1,
+				SeqI:  i + 1,
+				SeqJ:  j + 1,
+				Seq1:  seqs[i],
+				Seq2:  seqs[j],
+				Hash:  pairHash(seqs[i], seqs[j], fingerprint),
+			})
+		}
+	}
+	return jobs
+}
+
+// writeJournal guarda en path un resultado por trabajo.
+func writeJournal(t *testing.T, path string, resume bool, jobs []Job) {
+	t.Helper()
+	j, _, err := openJournal(path, resume)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, job := range jobs {
+		r := ComparisonResult{Index: job.Index, SeqI: job.SeqI, SeqJ: job.SeqJ, Hash: job.Hash, Text: job.Seq1 + " vs " + job.Seq2}
+		if err := j.Append(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readJournal lee las entradas de path como al reanudar.
+func readJournal(t *testing.T, path string) map[pairKey]journalEntry {
+	t.Helper()
+	j, done, err := openJournal(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return done
+}
+
+func TestJournalResumeTruncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	jobs := journalJobs([]string{"aCbbH", "CxxH", "HdC"}, compare.DefaultOptions())
+	writeJournal(t, path, false, jobs)
+
+	// Un corte a mitad de la última línea la deja incompleta
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data[:len(data)-10], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	done := readJournal(t, path)
+	if len(done) != 2 {
+		t.Fatalf("resume read %d entries, want 2 (last line is incomplete)", len(done))
+	}
+	if _, ok := done[pairKey{jobs[2].SeqI, jobs[2].SeqJ}]; ok {
+		t.Errorf("the incomplete entry must be discarded")
+	}
+
+	// Lo que se agrega después empieza en una línea nueva
+	writeJournal(t, path, true, jobs[2:])
+	done = readJournal(t, path)
+	if len(done) != 3 {
+		t.Errorf("after appending, resume read %d entries, want 3", len(done))
+	}
+	if got := restoreJobs(jobs, done); got != 3 {
+		t.Errorf("restored %d jobs, want 3", got)
+	}
+	for _, job := range jobs {
+		if job.Restored == nil || job.Restored.Text != job.Seq1+" vs "+job.Seq2 || !job.Restored.Restored {
+			t.Errorf("job %d restored as %+v", job.Index, job.Restored)
+		}
+	}
+
+	// Sin -resume el journal se trunca
+	writeJournal(t, path, false, nil)
+	if done = readJournal(t, path); len(done) != 0 {
+		t.Errorf("a new journal kept %d entries", len(done))
+	}
+}
+
+func TestJournalInvalidation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	seqs := []string{"aCbbH", "CxxH", "HdC"}
+	opts := compare.DefaultOptions()
+	writeJournal(t, path, false, journalJobs(seqs, opts))
+	done := readJournal(t, path)
+
+	if got := restoreJobs(journalJobs(seqs, opts), done); got != 3 {
+		t.Errorf("same options: restored %d jobs, want 3", got)
+	}
+
+	// Una opción que cambia el resultado invalida todas las entradas
+	changed := opts
+	changed.MaxLCS = 5
+	if got := restoreJobs(journalJobs(seqs, changed), done); got != 0 {
+		t.Errorf("-max-lcs changed: restored %d jobs, want 0", got)
+	}
+	// También las que solo cuenta la huella de compare (clave del caché)
+	changed = opts
+	changed.Match = gaps.MatchStrict
+	if got := restoreJobs(journalJobs(seqs, changed), done); got != 0 {
+		t.Errorf("-match changed: restored %d jobs, want 0", got)
+	}
+	changed = opts
+	changed.Seed = 7
+	if got := restoreJobs(journalJobs(seqs, changed), done); got != 0 {
+		t.Errorf("-seed changed: restored %d jobs, want 0", got)
+	}
+
+	// Otro contenido en la misma posición invalida solo sus pares
+	jobs := journalJobs([]string{"aCbbH", "CxxH", "HdD"}, opts)
+	if got := restoreJobs(jobs, done); got != 1 {
+		t.Errorf("third sequence changed: restored %d jobs, want 1", got)
+	}
+	if jobs[0].Restored == nil {
+		t.Errorf("the unchanged pair 1 vs 2 must be restored")
+	}
+}
@@ -1,66 +1,230 @@
 package main
 
 import (
-	"bufio"
+	"context"
 	"encoding/csv"
 	"flag"
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
-	"regexp"
+	"os/signal"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"unicode"
 
+	"github.com/lucckkas/patternfinder/internal/cache"
+	"github.com/lucckkas/patternfinder/internal/compare"
+	"github.com/lucckkas/patternfinder/internal/enrich"
 	"github.com/lucckkas/patternfinder/internal/gaps"
+	"github.com/lucckkas/patternfinder/internal/lcs"
+	"github.com/lucckkas/patternfinder/internal/progress"
+	"github.com/lucckkas/patternfinder/internal/seqio"
+	"github.com/lucckkas/patternfinder/internal/utils"
 )
 
 func main() {
-	inputFile := flag.String("f", "", "archivo de texto con las secuencias (una por línea)")
-	patternfinderPath := flag.String("p", "./build/patternfinder", "ruta al ejecutable de patternfinder")
-	showDP := flag.Bool("dp", false, "pasar flag -dp a patternfinder")
-	seq := flag.Bool("seq", false, "pasar flag -seq a patternfinder")
+	inputFile := flag.String("f", "", "archivo con las secuencias: una por línea, FASTA o Segmentos.json")
+	showDP := flag.Bool("dp", false, "mostrar la matriz LCS de cada comparación")
+	seq := flag.Bool("seq", false, "ejecutar las comparaciones de forma secuencial")
 	outputFile := flag.String("o", "", "archivo de salida para los resultados (opcional, por defecto stdout)")
 	workers := flag.Int("w", 6, "número de workers paralelos para ejecutar comparaciones")
 	csvFile := flag.String("csv", "", "archivo CSV para guardar estadísticas de patrones")
+	minLCS := flag.Int("min-lcs", 1, "omitir pares cuya LCS (en mayúsculas) sea más corta que este valor (prefiltro bit-paralelo)")
+	classesSpec := flag.String("classes", "", "clases de residuos equivalentes (preset o grupos como [CH][DE])")
+	mode := flag.String("mode", "pairs", "modo: pairs (comparaciones por pares) o consensus (patrones comunes a varias secuencias)")
+	minFrac := flag.Float64("min-frac", 1.0, "fracción mínima de secuencias que deben contener el patrón (-mode consensus)")
+	top := flag.Int("top", 10, "cantidad máxima de patrones a reportar (-mode consensus, 0 = todos)")
+	ligand := flag.String("ligand", "", "Segmentos.json: solo ligandos cuyo código empieza con este prefijo (ej. ZN)")
+	proteins := flag.String("proteins", "", "Segmentos.json: lista de proteínas separadas por coma (ej. 1a1f,1tf3)")
+	chains := flag.String("chain", "", "Segmentos.json: cadenas del ligando separadas por coma (ej. A,B)")
+	journalPath := flag.String("journal", "", "journal (JSON Lines) donde se guarda cada comparación al terminar")
+	resume := flag.Bool("resume", false, "reanudar desde -journal, omitiendo las comparaciones ya guardadas")
+	timeout := flag.Duration("timeout", 0, "tiempo máximo por comparación, ej. 30s; al vencer se guardan los resultados parciales (0 = sin límite)")
+	memMB := flag.Int("mem", 1024, "presupuesto de memoria (MB) para la tabla DP de cada par; si se excede se usa el modo lineal (Hirschberg); 0 = sin límite")
+	maxLCS := flag.Int("max-lcs", 10000, "máximo de LCS distintas por par; si hay más se rechaza el par (o se muestrea con -sample); 0 = sin límite")
+	sample := flag.Bool("sample", false, "si se supera -max-lcs, muestrear -max-lcs LCS distintas en vez de rechazar el par")
+	maxComb := flag.Int("max-comb", 100000, "máximo de combinaciones de gaps a generar por patrón; si hay más se informa solo la cantidad (0 = sin límite)")
+	dedup := flag.String("dedup", seqio.DedupExact, "agrupar secuencias antes de comparar: none, exact (idénticas) o upper (mismas mayúsculas, aproximado)")
+	noCache := flag.Bool("no-cache", false, "no leer ni guardar resultados en el caché")
+	cacheDir := flag.String("cache-dir", cache.DefaultDir(), "directorio del caché de resultados por par")
+	cacheMB := flag.Int("cache-size", cache.DefaultMaxBytes/(1024*1024), "tamaño máximo del caché en MB; al superarlo se borran las entradas menos usadas (0 = sin límite)")
+	progressMode := flag.String("progress", "auto", "progreso en stderr: auto, bar, plain, json o none")
+	perm := flag.Int("perm", 0, "permutaciones del modelo nulo para calcular p-valores y q-valores de los patrones del CSV (0 = no calcular)")
+	null := flag.String("null", enrich.NullShuffle, "modelo nulo para -perm: shuffle (permutar cada secuencia) o markov (cadena de Markov de orden 1)")
+	seed := flag.Int64("seed", 1, "semilla del modelo nulo (-perm)")
+	negFile := flag.String("neg", "", "conjunto negativo (mismos formatos que -f): los patrones hallados en -f se buscan en ambos y se ordenan por cuánto los distinguen")
 	flag.Parse()
 
 	if *inputFile == "" {
-		fmt.Fprintf(os.Stderr, "Uso: %s -f <archivo_secuencias> [-p <path_patternfinder>] [-dp] [-seq] [-w <workers>] [-o <archivo_salida>] [-csv <archivo_csv>]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Uso: %s -f <archivo_secuencias> [-dp] [-seq] [-w <workers>] [-o <archivo_salida>] [-csv <archivo_csv>]\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "\nOpciones:\n")
-		fmt.Fprintf(os.Stderr, "  -f <archivo>     Archivo de secuencias (una por línea) [REQUERIDO]\n")
-		fmt.Fprintf(os.Stderr, "  -p <path>        Ruta al ejecutable de patternfinder (default: ./build/patternfinder)\n")
-		fmt.Fprintf(os.Stderr, "  -seq             Modo SECUENCIAL: ejecuta comparaciones una por una y pasa -seq a patternfinder\n")
+		fmt.Fprintf(os.Stderr, "  -f <archivo>     Archivo de secuencias (una por línea, FASTA o Segmentos.json) [REQUERIDO]\n")
+		fmt.Fprintf(os.Stderr, "  -seq             Modo SECUENCIAL: ejecuta comparaciones una por una\n")
 		fmt.Fprintf(os.Stderr, "  -w <número>      Número de workers para modo PARALELO (default: 6, ignorado si -seq)\n")
-		fmt.Fprintf(os.Stderr, "  -dp              Pasar flag -dp a patternfinder (muestra matriz LCS)\n")
+		fmt.Fprintf(os.Stderr, "  -dp              Muestra la matriz LCS de cada comparación\n")
 		fmt.Fprintf(os.Stderr, "  -o <archivo>     Archivo de salida para resultados (default: stdout)\n")
 		fmt.Fprintf(os.Stderr, "  -csv <archivo>   Genera CSV con estadísticas de patrones\n")
+		fmt.Fprintf(os.Stderr, "  -min-lcs <n>     Omite pares con LCS más corta que n sin compararlos (default: 1)\n")
+		fmt.Fprintf(os.Stderr, "  -classes <spec>  Compara por clases de residuos: %s o grupos como [CH][DE]\n", strings.Join(lcs.PresetNames(), ", "))
+		fmt.Fprintf(os.Stderr, "  -mode <modo>     pairs (default) o consensus: patrones comunes a varias secuencias\n")
+		fmt.Fprintf(os.Stderr, "  -min-frac <f>    Fracción mínima de secuencias con el patrón en modo consensus (default: 1.0)\n")
+		fmt.Fprintf(os.Stderr, "  -top <n>         Patrones a reportar en modo consensus (default: 10)\n")
+		fmt.Fprintf(os.Stderr, "  -ligand <código> Segmentos.json: filtra por prefijo del código de ligando (ej. ZN)\n")
+		fmt.Fprintf(os.Stderr, "  -proteins <l>    Segmentos.json: filtra por proteínas (lista separada por comas)\n")
+		fmt.Fprintf(os.Stderr, "  -chain <l>       Segmentos.json: filtra por cadena del ligando (lista separada por comas)\n")
+		fmt.Fprintf(os.Stderr, "  -journal <arch>  Guarda cada comparación terminada en un journal para poder reanudar\n")
+		fmt.Fprintf(os.Stderr, "  -resume          Reanuda desde -journal omitiendo las comparaciones ya hechas\n")
+		fmt.Fprintf(os.Stderr, "  -timeout <dur>   Tiempo máximo por comparación (ej. 30s); guarda resultados parciales\n")
+		fmt.Fprintf(os.Stderr, "  -mem <MB>        Memoria para la tabla DP de cada par; si no alcanza, modo lineal (default: 1024)\n")
+		fmt.Fprintf(os.Stderr, "  -max-lcs <n>     Máximo de LCS distintas por par; si hay más se rechaza (default: 10000)\n")
+		fmt.Fprintf(os.Stderr, "  -sample          Muestrea -max-lcs LCS en vez de rechazar el par\n")
+		fmt.Fprintf(os.Stderr, "  -max-comb <n>    Máximo de combinaciones por patrón; si hay más no se generan (default: 100000)\n")
+		fmt.Fprintf(os.Stderr, "  -dedup <modo>    Compara una sola vez las secuencias repetidas: none, exact (default) o upper\n")
+		fmt.Fprintf(os.Stderr, "  -no-cache        No usa el caché de resultados por par\n")
+		fmt.Fprintf(os.Stderr, "  -cache-dir <dir> Directorio del caché (default: %s)\n", cache.DefaultDir())
+		fmt.Fprintf(os.Stderr, "  -cache-size <MB> Tamaño máximo del caché; borra las entradas menos usadas (default: %d)\n", cache.DefaultMaxBytes/(1024*1024))
+		fmt.Fprintf(os.Stderr, "  -progress <modo> Progreso en stderr: auto (barra en terminal), bar, plain, json o none\n")
+		fmt.Fprintf(os.Stderr, "  -perm <n>        Permutaciones del modelo nulo; agrega p-valores y q-valores al CSV (default: 0, no)\n")
+		fmt.Fprintf(os.Stderr, "  -null <modelo>   Modelo nulo: shuffle (default) o markov\n")
+		fmt.Fprintf(os.Stderr, "  -seed <n>        Semilla del modelo nulo (default: 1)\n")
+		fmt.Fprintf(os.Stderr, "  -neg <archivo>   Conjunto negativo: ordena los patrones del CSV por soporte, odds ratio y test de Fisher\n")
 		fmt.Fprintf(os.Stderr, "\nModos de ejecución:\n")
 		fmt.Fprintf(os.Stderr, "  PARALELO (default): Usa múltiples workers para acelerar las comparaciones\n")
 		fmt.Fprintf(os.Stderr, "  SECUENCIAL (-seq): Ejecuta comparaciones una a la vez (útil para debugging)\n")
 		os.Exit(2)
 	}
 
+	if *mode != "pairs" && *mode != "consensus" {
+		fmt.Fprintf(os.Stderr, "Modo desconocido %q (use pairs o consensus)\n", *mode)
+		os.Exit(2)
+	}
+	if *dedup != seqio.DedupNone && *dedup != seqio.DedupExact && *dedup != seqio.DedupUpper {
+		fmt.Fprintf(os.Stderr, "Deduplicación desconocida %q (use none, exact o upper)\n", *dedup)
+		os.Exit(2)
+	}
+	if *resume && *journalPath == "" {
+		fmt.Fprintf(os.Stderr, "-resume requiere -journal <archivo>\n")
+		os.Exit(2)
+	}
+	if err := progress.CheckMode(*progressMode); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(2)
+	}
+	if *timeout < 0 || *memMB < 0 || *maxLCS < 0 || *maxComb < 0 {
+		fmt.Fprintf(os.Stderr, "-timeout, -mem, -max-lcs y -max-comb no pueden ser negativos\n")
+		os.Exit(2)
+	}
+	if *perm < 0 {
+		fmt.Fprintf(os.Stderr, "-perm no puede ser negativo\n")
+		os.Exit(2)
+	}
+	if *perm > 0 && *csvFile == "" {
+		fmt.Fprintf(os.Stderr, "-perm requiere -csv <archivo>\n")
+		os.Exit(2)
+	}
+	if *negFile != "" && *csvFile == "" {
+		fmt.Fprintf(os.Stderr, "-neg requiere -csv <archivo>\n")
+		os.Exit(2)
+	}
+	if err := enrich.CheckNull(*null); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(2)
+	}
+	if *minFrac <= 0 || *minFrac > 1 {
+		fmt.Fprintf(os.Stderr, "-min-frac debe estar en (0, 1]\n")
+		os.Exit(2)
+	}
+
+	classes, err := lcs.ParseClasses(*classesSpec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(2)
+	}
+
 	// Leer las secuencias del archivo
-	sequences, err := readSequences(*inputFile)
+	records, format, err := seqio.ReadFile(*inputFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error al leer el archivo: %v\n", err)
 		os.Exit(1)
 	}
 
+	filter := seqio.Filter{Ligand: *ligand, Proteins: seqio.SplitList(*proteins), Chains: seqio.SplitList(*chains)}
+	if !filter.Empty() {
+		if format != seqio.FormatSegments {
+			fmt.Fprintf(os.Stderr, "Los filtros -ligand, -proteins y -chain solo se aplican a archivos Segmentos.json\n")
+			os.Exit(2)
+		}
+		total := len(records)
+		records = filter.Apply(records)
+		fmt.Printf("Filtro de segmentos: %d de %d seleccionados\n", len(records), total)
+	}
+
+	sequences := seqio.Seqs(records)
+
+	// Conjunto negativo (-neg): se lee antes de comparar para fallar rápido
+	var negatives []string
+	if *negFile != "" {
+		negRecords, _, err := seqio.ReadFile(*negFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error al leer el conjunto negativo: %v\n", err)
+			os.Exit(1)
+		}
+		if len(negRecords) == 0 {
+			fmt.Fprintf(os.Stderr, "El conjunto negativo %s no tiene secuencias.\n", *negFile)
+			os.Exit(1)
+		}
+		negatives = seqio.Seqs(negRecords)
+	}
+	labels := make([]string, len(records))
+	ids := make([]string, len(records))
+	for i, rec := range records {
+		labels[i] = seqLabel(rec, format)
+		ids[i] = rec.ID
+	}
+
 	if len(sequences) < 2 {
 		fmt.Fprintf(os.Stderr, "Se necesitan al menos 2 secuencias en el archivo.\n")
 		os.Exit(1)
 	}
 
-	fmt.Printf("Leyendo %d secuencias del archivo %s\n", len(sequences), *inputFile)
-	fmt.Printf("Total de comparaciones: %d\n", (len(sequences)*(len(sequences)-1))/2)
-	if *seq {
-		fmt.Printf("Ejecutando en modo SECUENCIAL\n\n")
+	// Deduplicación: solo se comparan los representantes de cada grupo (y
+	// cada grupo con copias consigo mismo, como lo harían sus miembros entre
+	// sí); los patrones de un par cuentan para todos los miembros de ambos
+	// grupos, así las cantidades y porcentajes del CSV no cambian
+	groups := seqio.Dedup(sequences, *dedup)
+	members := make(map[int][]int) // representante → miembros (base 1)
+	pairLabels := append([]string(nil), labels...)
+	var duplicated []string
+	pairCount := len(groups) * (len(groups) - 1) / 2
+	for _, g := range groups {
+		names := make([]string, len(g.Members))
+		for k, i := range g.Members {
+			members[g.Rep+1] = append(members[g.Rep+1], i+1)
+			names[k] = labels[i]
+		}
+		if len(g.Members) > 1 {
+			pairLabels[g.Rep] = fmt.Sprintf("%s (×%d)", labels[g.Rep], len(g.Members))
+			duplicated = append(duplicated, strings.Join(names, " = "))
+			pairCount++
+		}
+	}
+
+	fmt.Printf("Leyendo %d secuencias del archivo %s (%s)\n", len(sequences), *inputFile, format)
+	if *mode == "consensus" {
+		fmt.Printf("Modo CONSENSO: patrones en al menos %.2f%% de las secuencias\n\n", *minFrac*100)
 	} else {
-		fmt.Printf("Ejecutando con %d workers en paralelo\n\n", *workers)
+		if len(groups) < len(sequences) {
+			fmt.Printf("Deduplicación (%s): %d secuencias únicas de %d\n", *dedup, len(groups), len(sequences))
+			printGuarded("Grupos de secuencias repetidas", duplicated)
+		}
+		fmt.Printf("Total de comparaciones: %d\n", pairCount)
+		if *seq {
+			fmt.Printf("Ejecutando en modo SECUENCIAL\n\n")
+		} else {
+			fmt.Printf("Ejecutando con %d workers en paralelo\n\n", *workers)
+		}
 	}
 
 	// Configurar salida
@@ -77,24 +241,45 @@ func main() {
 		output = os.Stdout
 	}
 
-	// Verificar que el ejecutable de patternfinder existe
-	if _, err := os.Stat(*patternfinderPath); os.IsNotExist(err) {
-		fmt.Fprintf(os.Stderr, "El ejecutable de patternfinder no existe en: %s\n", *patternfinderPath)
-		os.Exit(1)
+	enrichOpts := enrich.Options{Null: *null, Permutations: *perm, Seed: *seed, Workers: *workers}
+
+	if *mode == "consensus" {
+		stats := runConsensus(output, sequences, ids, *minFrac, *top, classes)
+		fmt.Printf("\nPatrones de consenso encontrados: %d\n", len(stats))
+		if *csvFile != "" {
+			significance := enrichStats(context.Background(), sequences, stats, enrichOpts)
+			contrast := discriminateStats(sequences, negatives, stats)
+			if err := generateCSV(*csvFile, stats, records, significance, contrast); err != nil {
+				fmt.Fprintf(os.Stderr, "Error al generar CSV: %v\n", err)
+			} else {
+				fmt.Printf("Estadísticas de patrones guardadas en: %s\n", *csvFile)
+			}
+		}
+		return
 	}
 
-	// Obtener la ruta absoluta del ejecutable
-	absPath, err := filepath.Abs(*patternfinderPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error al obtener ruta absoluta: %v\n", err)
-		os.Exit(1)
-	}
+	// Opciones de cada comparación (mismos defaults que patternfinder; cada
+	// par usa la versión secuencial del LCS, el paralelismo está entre pares)
+	opts := compare.DefaultOptions()
+	opts.Sequential = true
+	opts.KeepDP = *showDP
+	opts.Classes = classes
+	opts.Timeout = *timeout
+	opts.MemBudget = int64(*memMB) * 1024 * 1024
+	opts.MaxLCS = *maxLCS
+	opts.Sample = *sample
+	opts.MaxComb = *maxComb
 
 	// Crear lista de trabajos (pares de secuencias a comparar)
+	fingerprint := journalFingerprint(opts, *minLCS)
 	var jobs []Job
 	comparisonCount := 0
-	for i := 0; i < len(sequences); i++ {
-		for j := i + 1; j < len(sequences); j++ {
+	for a := range groups {
+		for b := a; b < len(groups); b++ {
+			if b == a && len(groups[a].Members) == 1 {
+				continue
+			}
+			i, j := groups[a].Rep, groups[b].Rep
 			comparisonCount++
 			jobs = append(jobs, Job{
 				Index: comparisonCount,
@@ -102,44 +287,139 @@ func main() {
 				SeqJ:  j + 1,
 				Seq1:  sequences[i],
 				Seq2:  sequences[j],
+				Label: pairLabels[i] + " vs " + pairLabels[j],
+				Hash:  pairHash(sequences[i], sequences[j], fingerprint),
 			})
 		}
 	}
 
-	// Mapa para recolectar estadísticas de patrones
+	// Journal: cada comparación terminada se agrega al archivo; con -resume
+	// se recuperan las ya hechas (mismo par y mismo contenido)
+	var journal *Journal
+	recovered := 0
+	if *journalPath != "" {
+		var done map[pairKey]journalEntry
+		journal, done, err = openJournal(*journalPath, *resume)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error al abrir el journal: %v\n", err)
+			os.Exit(1)
+		}
+		recovered = restoreJobs(jobs, done)
+		if *resume {
+			fmt.Printf("Reanudando desde %s: %d comparaciones recuperadas, %d pendientes\n\n", *journalPath, recovered, len(jobs)-recovered)
+		}
+	}
+	onDone := func(r ComparisonResult) {
+		if journal == nil || r.Restored {
+			return
+		}
+		if err := journal.Append(r); err != nil {
+			fmt.Fprintf(os.Stderr, "Error al escribir el journal: %v\n", err)
+		}
+	}
+
+	// Escribir resultados en orden apenas están disponibles y recolectar
+	// patrones de forma incremental
 	patternStats := make(map[string]*gaps.PatternStat)
+	written, skipped := 0, 0
+	var timedOut, rejected []string // "Comparación N: A vs B"
+	linear, omitted := 0, 0
+	onOrdered := func(result ComparisonResult) {
+		written++
+		fmt.Fprintf(output, "========================================\n")
+		fmt.Fprintf(output, "Comparación %d: %s vs %s\n", result.Index, pairLabels[result.SeqI-1], pairLabels[result.SeqJ-1])
+		fmt.Fprintf(output, "========================================\n")
+
+		if result.Skipped {
+			skipped++
+			fmt.Fprintf(output, "Omitida por prefiltro: LCS de longitud %d (mínimo %d)\n", result.LCSLength, *minLCS)
+		} else {
+			fmt.Fprintf(output, "%s", result.Text)
+			// Recolectar los patrones del resultado
+			collectPatterns(result.Report, patternStats, members[result.SeqI], members[result.SeqJ])
+
+			name := fmt.Sprintf("Comparación %d: %s vs %s", result.Index, pairLabels[result.SeqI-1], pairLabels[result.SeqJ-1])
+			switch result.Report.Status {
+			case compare.StatusTimeout:
+				timedOut = append(timedOut, name)
+			case compare.StatusRejected:
+				rejected = append(rejected, name)
+			}
+			if result.Report.Linear {
+				linear++
+			}
+			for _, p := range result.Report.Patterns {
+				if p.Omitted != "" {
+					omitted++
+				}
+			}
+		}
+
+		fmt.Fprintf(output, "\n")
+	}
+
+	// Ctrl-C / SIGTERM: no se lanzan más comparaciones; las que están en curso
+	// se cortan (ver compare.Pair) y no se guardan en el journal
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Caché de resultados por par, compartido entre corridas
+	var pairCache *cache.Cache
+	if !*noCache {
+		if pairCache, err = cache.Open(*cacheDir, int64(*cacheMB)*1024*1024); err != nil {
+			fmt.Fprintf(os.Stderr, "Aviso: no se pudo abrir el caché (%v); se continúa sin caché\n", err)
+		}
+	}
+
+	// Progreso en stderr (los recuperados del journal ya cuentan como hechos)
+	rep, _ := progress.New(os.Stderr, *progressMode, len(jobs))
+	rep.Resumed(recovered)
+	rep.Start()
 
-	var resultMap map[int]ComparisonResult
+	r := runner{opts: opts, minLCS: *minLCS, progress: rep, cache: pairCache}
 
 	// Decidir entre ejecución secuencial o paralela
 	if *seq {
 		// Modo SECUENCIAL
-		resultMap = executeSequential(jobs, absPath, *showDP)
+		executeSequential(ctx, jobs, r.run, onDone, onOrdered)
 	} else {
 		// Modo PARALELO
-		resultMap = executeParallel(jobs, absPath, *showDP, *workers)
+		executeParallel(ctx, jobs, *workers, r.run, onDone, onOrdered)
 	}
+	rep.Finish()
 
-	// Escribir resultados en orden y recolectar patrones
-	for i := 1; i <= len(jobs); i++ {
-		result := resultMap[i]
-		fmt.Fprintf(output, "========================================\n")
-		fmt.Fprintf(output, "Comparación %d: Secuencia %d vs Secuencia %d\n", result.Index, result.SeqI, result.SeqJ)
-		fmt.Fprintf(output, "========================================\n")
-
-		if result.Error != nil {
-			fmt.Fprintf(output, "Error al ejecutar patternfinder: %v\n", result.Error)
-			fmt.Fprintf(output, "Salida: %s\n", result.Output)
-		} else {
-			fmt.Fprintf(output, "%s", result.Output)
-			// Extraer patrones de la salida
-			extractPatterns(result.Output, patternStats, result.SeqI, result.SeqJ)
+	if ctx.Err() != nil {
+		fmt.Fprintf(os.Stderr, "\nInterrumpido: %d de %d comparaciones escritas.\n", written, len(jobs))
+		if journal != nil {
+			if err := journal.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error al cerrar el journal: %v\n", err)
+			}
+			fmt.Fprintf(os.Stderr, "Progreso guardado en %s; use -journal %s -resume para continuar.\n", *journalPath, *journalPath)
+		}
+		output.Close()
+		os.Exit(130)
+	}
+	if journal != nil {
+		if err := journal.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error al cerrar el journal: %v\n", err)
 		}
-
-		fmt.Fprintf(output, "\n")
 	}
 
 	fmt.Printf("\nComparaciones completadas: %d\n", comparisonCount)
+	if skipped > 0 {
+		fmt.Printf("Comparaciones omitidas por prefiltro (LCS < %d): %d\n", *minLCS, skipped)
+	}
+	printGuarded(fmt.Sprintf("Comparaciones con tiempo agotado (-timeout %v), resultados parciales", *timeout), timedOut)
+	printGuarded(fmt.Sprintf("Comparaciones rechazadas por -max-lcs %d", *maxLCS), rejected)
+	if linear > 0 {
+		fmt.Printf("Comparaciones en modo lineal por -mem %d MB (una sola LCS): %d\n", *memMB, linear)
+	}
+	if pairCache != nil {
+		fmt.Printf("Caché (%s): %d pares reutilizados, %d calculados\n", pairCache.Dir(), pairCache.Hits(), pairCache.Misses())
+	}
+	if omitted > 0 {
+		fmt.Printf("Patrones con combinaciones omitidas por -max-comb %d (no cuentan en el CSV): %d\n", *maxComb, omitted)
+	}
 	if *outputFile != "" {
 		fmt.Printf("Resultados guardados en: %s\n", *outputFile)
 	}
@@ -150,7 +430,9 @@ func main() {
 		consolidatedStats := gaps.ConsolidatePatterns(patternStats)
 		fmt.Printf("Patrones antes de consolidar: %d, después: %d\n", len(patternStats), len(consolidatedStats))
 		
-		err := generateCSV(*csvFile, consolidatedStats, len(sequences))
+		significance := enrichStats(ctx, sequences, consolidatedStats, enrichOpts)
+		contrast := discriminateStats(sequences, negatives, consolidatedStats)
+		err := generateCSV(*csvFile, consolidatedStats, records, significance, contrast)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error al generar CSV: %v\n", err)
 		} else {
@@ -159,6 +441,24 @@ func main() {
 	}
 }
 
+// maxListed es cuántos pares se listan en el resumen de printGuarded.
+const maxListed = 20
+
+// printGuarded imprime cuántos pares cortó un límite y los primeros de ellos.
+func printGuarded(title string, pairs []string) {
+	if len(pairs) == 0 {
+		return
+	}
+	fmt.Printf("%s: %d\n", title, len(pairs))
+	for k, p := range pairs {
+		if k == maxListed {
+			fmt.Printf("  ... y %d más\n", len(pairs)-maxListed)
+			break
+		}
+		fmt.Printf("  - %s\n", p)
+	}
+}
+
 // Job representa un trabajo de comparación entre dos secuencias
 type Job struct {
 	Index int
@@ -166,56 +466,121 @@ type Job struct {
 	SeqJ  int
 	Seq1  string
 	Seq2  string
+	Label string // "A vs B", para el progreso
+	Hash  string // contenido del par + opciones (clave del journal)
+
+	Restored *ComparisonResult // resultado recuperado del journal (-resume)
 }
 
 // ComparisonResult almacena el resultado de una comparación
 type ComparisonResult struct {
-	Index  int
-	SeqI   int
-	SeqJ   int
-	Output string
-	Error  error
+	Index       int
+	SeqI        int
+	SeqJ        int
+	Hash        string
+	Text        string          // salida en texto (formato de patternfinder)
+	Report      *compare.Report // resultado tipado
+	Skipped     bool            // omitida por el prefiltro de longitud LCS
+	Restored    bool            // recuperado del journal, no recalculado
+	Interrupted bool            // cortado por Ctrl-C: no se escribe ni va al journal
+	LCSLength   int             // longitud LCS calculada por el prefiltro
 }
 
-// executeSequential ejecuta las comparaciones de forma secuencial
-func executeSequential(jobs []Job, absPath string, showDP bool) map[int]ComparisonResult {
-	resultMap := make(map[int]ComparisonResult)
+// prefilter calcula la longitud de la LCS entre las mayúsculas del par con el
+// algoritmo bit-paralelo y decide si vale la pena compararlo.
+// Con clases, la longitud se calcula sobre las secuencias traducidas.
+// Devuelve el resultado "omitido" y true si el par no alcanza minLCS.
+func prefilter(job Job, minLCS int, classes *lcs.Classes) (ComparisonResult, bool) {
+	if minLCS <= 0 {
+		return ComparisonResult{}, false
+	}
+	n := lcs.LengthBitParallel(classes.Translate(utils.UpperOnly(job.Seq1)), classes.Translate(utils.UpperOnly(job.Seq2)))
+	if n >= minLCS {
+		return ComparisonResult{}, false
+	}
+	return ComparisonResult{
+		Index:     job.Index,
+		SeqI:      job.SeqI,
+		SeqJ:      job.SeqJ,
+		Skipped:   true,
+		LCSLength: n,
+	}, true
+}
 
-	for _, job := range jobs {
-		// Preparar los argumentos para patternfinder
-		args := []string{}
-		if showDP {
-			args = append(args, "-dp")
-		}
-		// if useSeq {
-		args = append(args, "-seq")
-		// }
-		args = append(args, job.Seq1, job.Seq2)
+// runner reúne lo necesario para resolver un trabajo.
+type runner struct {
+	opts     compare.Options
+	minLCS   int
+	progress *progress.Reporter
+	cache    *cache.Cache // nil con -no-cache
+}
 
-		// Ejecutar patternfinder
-		cmd := exec.Command(absPath, args...)
-		cmdOutput, err := cmd.CombinedOutput()
+// run devuelve el resultado recuperado del journal o aplica el prefiltro y,
+// si el par lo pasa, lo toma del caché o lo compara. Los pares que no vienen
+// del journal se informan al progreso. Si ctx se cancela durante la
+// comparación, el resultado queda con Interrupted.
+func (r runner) run(ctx context.Context, job Job) ComparisonResult {
+	if job.Restored != nil {
+		return *job.Restored
+	}
+	r.progress.Begin(job.Index, job.Label)
+	defer r.progress.End(job.Index)
+	if result, skip := prefilter(job, r.minLCS, r.opts.Classes); skip {
+		result.Hash = job.Hash
+		return result
+	}
+	entry, hit := compare.PairCached(ctx, r.cache, job.Seq1, job.Seq2, r.opts)
+	return ComparisonResult{
+		Index:  job.Index,
+		SeqI:   job.SeqI,
+		SeqJ:   job.SeqJ,
+		Hash:   job.Hash,
+		Text:   entry.Text,
+		Report: &entry.Report,
+		// Ante la duda (cancelado justo al terminar) se descarta: al
+		// reanudar se vuelve a comparar, o se toma del caché si estaba completo
+		Interrupted: !hit && ctx.Err() != nil,
+	}
+}
 
-		result := ComparisonResult{
-			Index:  job.Index,
-			SeqI:   job.SeqI,
-			SeqJ:   job.SeqJ,
-			Output: string(cmdOutput),
-			Error:  err,
+// executeSequential ejecuta las comparaciones de forma secuencial hasta que
+// se cancele ctx; cada resultado se entrega a onDone y luego a onOrdered
+// (salvo los interrumpidos)
+func executeSequential(ctx context.Context, jobs []Job, run func(context.Context, Job) ComparisonResult, onDone, onOrdered func(ComparisonResult)) {
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			break
 		}
-
-		resultMap[job.Index] = result
+		result := run(ctx, job)
+		if result.Interrupted {
+			break
+		}
+		onDone(result)
+		onOrdered(result)
 	}
-
-	return resultMap
 }
 
+// reorderWindowPerWorker es cuántos resultados por worker pueden estar en
+// curso o esperando su turno en el buffer de reordenamiento.
+const reorderWindowPerWorker = 4
+
 // executeParallel ejecuta las comparaciones en paralelo con múltiples workers
-func executeParallel(jobs []Job, absPath string, showDP bool, workers int) map[int]ComparisonResult {
+// hasta que se cancele ctx. onDone recibe cada resultado apenas termina y
+// onOrdered los recibe en el orden de jobs (ambos desde una sola goroutine).
+// Un buffer de reordenamiento guarda los resultados que llegan antes de su
+// turno; para acotar la memoria, un trabajo solo se despacha si está a menos
+// de workers*reorderWindowPerWorker posiciones del próximo a escribir. Los
+// resultados interrumpidos se descartan, así la salida en orden se detiene en
+// el primero de ellos.
+func executeParallel(ctx context.Context, jobs []Job, workers int, run func(context.Context, Job) ComparisonResult, onDone, onOrdered func(ComparisonResult)) {
+	workers = max(workers, 1)
+	window := workers * reorderWindowPerWorker
+	slots := make(chan struct{}, window)
+
 	// Canal para enviar trabajos
-	jobsChan := make(chan Job, len(jobs))
+	jobsChan := make(chan Job)
 	// Canal para recibir resultados
-	resultsChan := make(chan ComparisonResult, len(jobs))
+	resultsChan := make(chan ComparisonResult, window)
 
 	// Lanzar workers
 	var wg sync.WaitGroup
@@ -224,37 +589,27 @@ func executeParallel(jobs []Job, absPath string, showDP bool, workers int) map[i
 		go func(workerID int) {
 			defer wg.Done()
 			for job := range jobsChan {
-				// Preparar los argumentos para patternfinder
-				args := []string{}
-				if showDP {
-					args = append(args, "-dp")
-				}
-				// if useSeq {
-				args = append(args, "-seq")
-				// }
-				args = append(args, job.Seq1, job.Seq2)
-
-				// Ejecutar patternfinder
-				cmd := exec.Command(absPath, args...)
-				cmdOutput, err := cmd.CombinedOutput()
-
-				result := ComparisonResult{
-					Index:  job.Index,
-					SeqI:   job.SeqI,
-					SeqJ:   job.SeqJ,
-					Output: string(cmdOutput),
-					Error:  err,
-				}
-				resultsChan <- result
+				resultsChan <- run(ctx, job)
 			}
 		}(w)
 	}
 
-	// Enviar todos los trabajos al canal
-	for _, job := range jobs {
-		jobsChan <- job
-	}
-	close(jobsChan)
+	// Despachar trabajos en orden, esperando lugar en la ventana
+	go func() {
+		defer close(jobsChan)
+		for _, job := range jobs {
+			select {
+			case <-ctx.Done():
+				return
+			case slots <- struct{}{}:
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case jobsChan <- job:
+			}
+		}
+	}()
 
 	// Esperar a que terminen todos los workers
 	go func() {
@@ -262,55 +617,44 @@ func executeParallel(jobs []Job, absPath string, showDP bool, workers int) map[i
 		close(resultsChan)
 	}()
 
-	// Recolectar todos los resultados
-	results := make([]ComparisonResult, 0, len(jobs))
+	// Buffer de reordenamiento: entregar en orden y liberar la ventana
+	pending := make(map[int]ComparisonResult)
+	next := 0
 	for result := range resultsChan {
-		results = append(results, result)
-	}
-
-	// Ordenar resultados por índice para mantener el orden original
-	resultMap := make(map[int]ComparisonResult)
-	for _, r := range results {
-		resultMap[r.Index] = r
-	}
-
-	return resultMap
-}
-
-// readSequences lee un archivo de texto y retorna un slice con las secuencias
-// Ignora líneas vacías y elimina espacios en blanco al inicio/final
-func readSequences(filename string) ([]string, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	var sequences []string
-	scanner := bufio.NewScanner(file)
-	lineNumber := 0
-
-	for scanner.Scan() {
-		lineNumber++
-		line := strings.TrimSpace(scanner.Text())
-
-		// Ignorar líneas vacías y comentarios
-		if line == "" || strings.HasPrefix(line, "#") {
+		if result.Interrupted {
 			continue
 		}
-
-		sequences = append(sequences, line)
+		onDone(result)
+		pending[result.Index] = result
+		for next < len(jobs) {
+			r, ok := pending[jobs[next].Index]
+			if !ok {
+				break
+			}
+			delete(pending, jobs[next].Index)
+			onOrdered(r)
+			next++
+			<-slots
+		}
 	}
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+// seqLabel devuelve cómo se nombra una secuencia en la salida: su
+// identificador si viene de un FASTA o de Segmentos.json (proteína/ligando/n),
+// o "Secuencia N" en texto plano
+func seqLabel(rec seqio.Record, format seqio.Format) string {
+	if format == seqio.FormatPlain {
+		return "Secuencia " + rec.ID
 	}
-
-	return sequences, nil
+	return rec.ID
 }
 
-// countUppercase cuenta las letras mayúsculas en una cadena
+// countUppercase cuenta las posiciones (residuos o clases) de un patrón
+// PROSITE; si no es un patrón válido, cuenta las letras mayúsculas
 func countUppercase(s string) int {
+	if pat, err := gaps.ParsePattern(s); err == nil {
+		return pat.Positions()
+	}
 	count := 0
 	for _, r := range s {
 		if unicode.IsUpper(r) {
@@ -320,17 +664,16 @@ func countUppercase(s string) int {
 	return count
 }
 
-// extractPatterns extrae los patrones de la salida de patternfinder
-func extractPatterns(output string, stats map[string]*gaps.PatternStat, seqI, seqJ int) {
-	// Buscar líneas que contienen patrones con el formato [n.m] pattern
-	// Ejemplo: [1.1] A-x(2)-B-x(3)-C
-	re := regexp.MustCompile(`\[\d+\.\d+\]\s+(.+)`)
-	lines := strings.Split(output, "\n")
-
-	for _, line := range lines {
-		matches := re.FindStringSubmatch(line)
-		if len(matches) > 1 {
-			pattern := strings.TrimSpace(matches[1])
+// collectPatterns agrega a stats las combinaciones de patrones de una
+// comparación (las mismas que patternfinder imprime como [n.m] patrón),
+// atribuidas a los miembros (base 1) de los grupos de ambas secuencias
+func collectPatterns(report *compare.Report, stats map[string]*gaps.PatternStat, membersI, membersJ []int) {
+	if report == nil {
+		return
+	}
+	for _, p := range report.Patterns {
+		for _, comb := range p.Combinations {
+			pattern := strings.TrimSpace(comb)
 			// Ignorar patrones vacíos
 			if pattern == "" {
 				continue
@@ -346,14 +689,24 @@ func extractPatterns(output string, stats map[string]*gaps.PatternStat, seqI, se
 			}
 
 			// Agregar las secuencias que contienen este patrón
-			stats[pattern].SequenceIndices[seqI] = true
-			stats[pattern].SequenceIndices[seqJ] = true
+			for _, i := range membersI {
+				stats[pattern].SequenceIndices[i] = true
+			}
+			for _, j := range membersJ {
+				stats[pattern].SequenceIndices[j] = true
+			}
 		}
 	}
 }
 
-// generateCSV genera un archivo CSV con las estadísticas de patrones
-func generateCSV(filename string, stats map[string]*gaps.PatternStat, totalSequences int) error {
+// generateCSV genera un archivo CSV con las estadísticas de patrones; las
+// últimas columnas listan los identificadores de las secuencias que lo
+// contienen y, con Segmentos.json, sus proteínas. Con significance (-perm)
+// se agregan el soporte observado y esperado, el p-valor y el q-valor; con
+// contrast (-neg), la comparación con el conjunto negativo, y las filas
+// quedan ordenadas de la más a la menos discriminativa
+func generateCSV(filename string, stats map[string]*gaps.PatternStat, records []seqio.Record, significance map[string]enrich.Result, contrast map[string]enrich.Contrast) error {
+	totalSequences := len(records)
 	file, err := os.Create(filename)
 	if err != nil {
 		return err
@@ -364,7 +717,14 @@ func generateCSV(filename string, stats map[string]*gaps.PatternStat, totalSeque
 	defer writer.Flush()
 
 	// Escribir encabezado
-	err = writer.Write([]string{"Patrón", "Cantidad de Mayúsculas", "Cantidad de Secuencias", "Porcentaje de Secuencias"})
+	header := []string{"Patrón", "Cantidad de Mayúsculas", "Cantidad de Secuencias", "Porcentaje de Secuencias", "Secuencias", "Proteínas"}
+	if significance != nil {
+		header = append(header, "Soporte observado", "Soporte esperado", "p-valor", "q-valor")
+	}
+	if contrast != nil {
+		header = append(header, "Rango discriminativo", "Soporte positivos", "Soporte negativos", "Diferencia de soporte", "Odds ratio", "p-valor Fisher")
+	}
+	err = writer.Write(header)
 	if err != nil {
 		return err
 	}
@@ -374,6 +734,9 @@ func generateCSV(filename string, stats map[string]*gaps.PatternStat, totalSeque
 	for pattern := range stats {
 		patterns = append(patterns, pattern)
 	}
+	if contrast != nil {
+		sort.Slice(patterns, func(i, j int) bool { return contrast[patterns[i]].Rank < contrast[patterns[j]].Rank })
+	}
 
 	// Escribir datos
 	for _, pattern := range patterns {
@@ -386,6 +749,28 @@ func generateCSV(filename string, stats map[string]*gaps.PatternStat, totalSeque
 			strconv.Itoa(stat.UppercaseCount),
 			strconv.Itoa(seqCount),
 			fmt.Sprintf("%.2f%%", percentage),
+			sequenceIDs(stat.SequenceIndices, records),
+			proteinList(stat.SequenceIndices, records),
+		}
+		if significance != nil {
+			sig := significance[pattern]
+			row = append(row,
+				strconv.Itoa(sig.Observed),
+				strconv.FormatFloat(sig.Expected, 'f', 2, 64),
+				strconv.FormatFloat(sig.PValue, 'g', 4, 64),
+				strconv.FormatFloat(sig.QValue, 'g', 4, 64),
+			)
+		}
+		if contrast != nil {
+			c := contrast[pattern]
+			row = append(row,
+				strconv.Itoa(c.Rank),
+				fmt.Sprintf("%d/%d", c.Pos, c.PosTotal),
+				fmt.Sprintf("%d/%d", c.Neg, c.NegTotal),
+				strconv.FormatFloat(c.SupportDiff, 'f', 4, 64),
+				strconv.FormatFloat(c.OddsRatio, 'g', 4, 64),
+				strconv.FormatFloat(c.PValue, 'g', 4, 64),
+			)
 		}
 
 		err = writer.Write(row)
@@ -396,3 +781,33 @@ func generateCSV(filename string, stats map[string]*gaps.PatternStat, totalSeque
 
 	return nil
 }
+
+// sequenceIDs devuelve los identificadores (índices base 1 en el mapa)
+// ordenados por posición en el archivo y separados por ';'
+func sequenceIDs(indices map[int]bool, records []seqio.Record) string {
+	idx := make([]int, 0, len(indices))
+	for i := range indices {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	ids := make([]string, len(idx))
+	for k, i := range idx {
+		ids[k] = records[i-1].ID
+	}
+	return strings.Join(ids, ";")
+}
+
+// proteinList devuelve las proteínas distintas (ordenadas, separadas por ';')
+// de las secuencias indicadas; vacío si no vienen de Segmentos.json
+func proteinList(indices map[int]bool, records []seqio.Record) string {
+	seen := make(map[string]bool)
+	var proteins []string
+	for i := range indices {
+		if p := records[i-1].Protein; p != "" && !seen[p] {
+			seen[p] = true
+			proteins = append(proteins, p)
+		}
+	}
+	sort.Strings(proteins)
+	return strings.Join(proteins, ";")
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/csv"
+	"flag"
 	"fmt"
 	"math/rand"
 	"os"
@@ -22,6 +23,9 @@ func generateRandomSequence(length int, seed int64) string {
 }
 
 func main() {
+	tile := flag.Int("tile", lcs.DefaultTileSize, "tamaño de bloque para la versión por bloques (DPTableTiled)")
+	flag.Parse()
+
 	lengths := []int{20, 30, 40, 50, 60, 70, 80, 90, 100, 120, 140, 160, 180, 200, 220, 240, 260}
 
 	// Crear archivo CSV
@@ -42,13 +46,14 @@ func main() {
 		"Par_DP_ms", "Par_BT_ms", "Par_Total_ms",
 		"Speedup_DP", "Speedup_BT", "Speedup_Total",
 		"LCS_Count",
+		"Tiled_DP_ms", "Speedup_Tiled_DP",
 	})
 
 	fmt.Println("Generando resultados de benchmark...")
 	fmt.Println("=====================================")
-	fmt.Printf("%-6s | %-10s | %-10s | %-10s | %-8s\n",
-		"Length", "Seq (ms)", "Par (ms)", "Speedup", "LCS#")
-	fmt.Println("-------|------------|------------|------------|--------")
+	fmt.Printf("%-6s | %-10s | %-10s | %-10s | %-8s | %-13s\n",
+		"Length", "Seq (ms)", "Par (ms)", "Speedup", "LCS#", "Tiled DP (ms)")
+	fmt.Println("-------|------------|------------|------------|----------|--------------")
 
 	for _, length := range lengths {
 		// Generar secuencias aleatorias
@@ -75,7 +80,13 @@ func main() {
 		parBTTime := time.Since(startBT)
 		parTotal := parDPTime + parBTTime
 
+		// Versión por bloques (solo tabla DP)
+		startDP = time.Now()
+		_ = lcs.DPTableTiled(seq1, seq2, *tile)
+		tiledDPTime := time.Since(startDP)
+
 		// Calcular speedup
+		speedupTiledDP := float64(seqDPTime) / float64(tiledDPTime)
 		speedupDP := float64(seqDPTime) / float64(parDPTime)
 		speedupBT := float64(seqBTTime) / float64(parBTTime)
 		speedupTotal := float64(seqTotal) / float64(parTotal)
@@ -93,15 +104,18 @@ func main() {
 			fmt.Sprintf("%.4f", speedupBT),
 			fmt.Sprintf("%.4f", speedupTotal),
 			fmt.Sprintf("%d", len(lcsSeq)),
+			fmt.Sprintf("%.6f", float64(tiledDPTime.Microseconds())/1000.0),
+			fmt.Sprintf("%.4f", speedupTiledDP),
 		})
 
 		// Imprimir en consola
-		fmt.Printf("%-6d | %-10.2f | %-10.2f | %-10.2fx | %-8d\n",
+		fmt.Printf("%-6d | %-10.2f | %-10.2f | %-10.2fx | %-8d | %-13.2f\n",
 			length,
 			float64(seqTotal.Microseconds())/1000.0,
 			float64(parTotal.Microseconds())/1000.0,
 			speedupTotal,
 			len(lcsSeq),
+			float64(tiledDPTime.Microseconds())/1000.0,
 		)
 	}
 
@@ -1,89 +1,119 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
-	"sort"
+	"os/signal"
 
-	"github.com/lucckkas/patternfinder/internal/aggregate"
+	"github.com/lucckkas/patternfinder/internal/align"
+	"github.com/lucckkas/patternfinder/internal/cache"
+	"github.com/lucckkas/patternfinder/internal/compare"
 	"github.com/lucckkas/patternfinder/internal/gaps"
 	"github.com/lucckkas/patternfinder/internal/lcs"
-	"github.com/lucckkas/patternfinder/internal/utils"
 )
 
 func main() {
 	showDP := flag.Bool("dp", false, "imprimir matriz LCS (longitudes)")
 	seq := flag.Bool("seq", false, "usar versión secuencial del LCS")
+	memMB := flag.Int("mem", 1024, "presupuesto de memoria (MB) para la tabla DP; si se excede se usa el modo lineal (Hirschberg); 0 = sin límite")
+	maxLCS := flag.Int("max-lcs", 10000, "máximo de LCS distintas a enumerar; si hay más se rechaza el par (o se muestrea con -sample, o se trunca con -limit/-timeout); 0 = sin límite")
+	sample := flag.Bool("sample", false, "si se supera -max-lcs, muestrear -max-lcs LCS distintas en vez de rechazar")
+	seed := flag.Int64("seed", 1, "semilla para el muestreo de LCS (-sample)")
+	limit := flag.Int("limit", 0, "detener la enumeración tras esta cantidad de LCS distintas (0 = sin límite)")
+	timeout := flag.Duration("timeout", 0, "tiempo máximo de enumeración de LCS, ej. 30s (0 = sin límite)")
+	maxComb := flag.Int("max-comb", 0, "máximo de combinaciones de gaps a generar por patrón; si hay más se informa solo la cantidad (0 = sin límite)")
+	classesSpec := flag.String("classes", "", "clases de residuos equivalentes: preset (metal, charge, basic, chemical) o grupos como [CH][DE]")
+	match := flag.String("match", gaps.MatchDefault, "semántica de los gaps: default (distancia total), strict (gaps solo con minúsculas), relaxed (residuos en cualquier caso) o interaction (los gaps cuentan solo mayúsculas)")
+	mode := flag.String("mode", "lcs", "modo de comparación: lcs (subsecuencia común) o align (alineamiento con matriz de sustitución)")
+	matrixPath := flag.String("matrix", "", "matriz de sustitución en formato NCBI para -mode align (default: BLOSUM62 incluida)")
+	gapOpen := flag.Int("gap-open", align.DefaultGapOpen, "penalización por abrir un gap (-mode align)")
+	gapExtend := flag.Int("gap-extend", align.DefaultGapExtend, "penalización por extender un gap (-mode align)")
+	local := flag.Bool("local", false, "alineamiento local (Smith-Waterman) en vez de global (-mode align)")
+	maxAlign := flag.Int("max-align", align.DefaultMaxAlignments, "máximo de alineamientos co-óptimos a enumerar (-mode align)")
+	format := flag.String("format", "text", "formato de salida: text, json o jsonl (una línea por comparación)")
+	noCache := flag.Bool("no-cache", false, "no leer ni guardar resultados en el caché")
+	cacheDir := flag.String("cache-dir", cache.DefaultDir(), "directorio del caché de resultados por par")
+	cacheMB := flag.Int("cache-size", cache.DefaultMaxBytes/(1024*1024), "tamaño máximo del caché en MB; al superarlo se borran las entradas menos usadas (0 = sin límite)")
 	flag.Parse()
 
-	args := flag.Args()
-	if len(args) != 2 {
-		fmt.Fprintf(os.Stderr, "Uso: %s <seq1> <seq2>\n", os.Args[0])
+	if *format != "text" && *format != "json" && *format != "jsonl" {
+		fmt.Fprintf(os.Stderr, "Formato desconocido %q (use text, json o jsonl)\n", *format)
 		os.Exit(2)
 	}
 
-	seqX := args[0]
-	seqY := args[1]
-
-	Ux := utils.UpperOnly(seqX)
-	Uy := utils.UpperOnly(seqY)
-
-	if len(Ux) == 0 || len(Uy) == 0 {
-		fmt.Println("No hay mayúsculas en alguna secuencia; no existe LCS.")
-		return
-	}
-
-	var (
-		dp  [][]int
-		all []string
-	)
-	if *seq {
-		dp = lcs.DPTable(Ux, Uy)
-		all = lcs.Backtracking(Ux, Uy, dp)
-	} else {
-		dp = lcs.DPTableParallel(Ux, Uy)
-		all = lcs.BacktrackingParallel(Ux, Uy, dp)
+	classes, err := lcs.ParseClasses(*classesSpec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(2)
 	}
-	if *showDP {
-		fmt.Println("Matriz LCS (longitudes):")
-		lcs.PrintDP(Ux, Uy, dp)
+	if err := gaps.CheckMatch(*match); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(2)
 	}
 
-	if len(all) == 0 {
-		fmt.Println("No se encontraron LCS.")
-		return
+	if *mode != compare.ModeLCS && *mode != compare.ModeAlign {
+		fmt.Fprintf(os.Stderr, "Modo desconocido %q (use lcs o align)\n", *mode)
+		os.Exit(2)
+	}
+	alignOpts := align.Options{
+		GapOpen:       *gapOpen,
+		GapExtend:     *gapExtend,
+		Local:         *local,
+		MaxAlignments: *maxAlign,
 	}
-	sort.Slice(all, func(i, j int) bool {
-		if len(all[i]) != len(all[j]) {
-			return len(all[i]) > len(all[j])
+	if *mode == compare.ModeAlign {
+		if *matrixPath != "" {
+			alignOpts.Matrix, err = align.LoadMatrix(*matrixPath)
+		} else {
+			alignOpts.Matrix = align.BLOSUM62()
 		}
-		return all[i] < all[j]
-	})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error al leer la matriz: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-	fmt.Printf("Secuencia 1 (original): %s\n", seqX)
-	fmt.Printf("Secuencia 2 (original): %s\n", seqY)
-	fmt.Printf("Mayúsculas 1: %s\n", Ux)
-	fmt.Printf("Mayúsculas 2: %s\n\n", Uy)
-	fmt.Printf("LCS: %v\n\n", all)
+	args := flag.Args()
+	if len(args) != 2 {
+		fmt.Fprintf(os.Stderr, "Uso: %s <seq1> <seq2>\n", os.Args[0])
+		os.Exit(2)
+	}
 
-	for idx, pat := range all {
-		setsX, okX := gaps.AllGapValuesDistanceTotalViable(seqX, pat)
-		setsY, okY := gaps.AllGapValuesDistanceTotalViable(seqY, pat)
-		if !okX || !okY {
-			fmt.Printf("[%d] %s -> (no se pudo calcular gaps)\n", idx+1, pat)
-			continue
-		}
-		union := aggregate.PairUnionSets(setsX, setsY)
+	opts := compare.Options{
+		Mode:       *mode,
+		Sequential: *seq,
+		KeepDP:     *showDP,
+		MemBudget:  int64(*memMB) * 1024 * 1024,
+		MaxLCS:     *maxLCS,
+		Sample:     *sample,
+		Seed:       *seed,
+		Limits:     lcs.Limits{MaxCount: *limit, MaxTime: *timeout},
+		MaxComb:    *maxComb,
+		Classes:    classes,
+		Match:      *match,
+		Align:      alignOpts,
+	}
 
-		// Generar todas las combinaciones de patrones
-		combinations := aggregate.ExpandPatternCombinations(pat, union)
+	// La enumeración incremental (-limit / -timeout) se puede cortar con Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
-		fmt.Printf("[%d] Patrón base: %s | valores: %v\n", idx+1, pat, union)
-		fmt.Printf("    Combinaciones (%d):\n", len(combinations))
-		for i, comb := range combinations {
-			fmt.Printf("    [%d.%d] %s\n", idx+1, i+1, comb)
+	var c *cache.Cache
+	if !*noCache {
+		if c, err = cache.Open(*cacheDir, int64(*cacheMB)*1024*1024); err != nil {
+			fmt.Fprintf(os.Stderr, "Aviso: no se pudo abrir el caché (%v); se continúa sin caché\n", err)
 		}
-		fmt.Println()
+	}
+
+	result, _ := compare.PairCached(ctx, c, args[0], args[1], opts)
+	if *format == "text" {
+		fmt.Print(result.Text)
+		return
+	}
+	if err := compare.WriteReport(os.Stdout, result.Report, *format == "jsonl"); err != nil {
+		fmt.Fprintf(os.Stderr, "Error al escribir JSON: %v\n", err)
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lucckkas/patternfinder/internal/gaps"
+	"github.com/lucckkas/patternfinder/internal/scan"
+	"github.com/lucckkas/patternfinder/internal/seqio"
+)
+
+// patternList acumula los -p repetidos (los patrones llevan comas, así que
+// no se puede usar una lista separada por comas).
+type patternList []string
+
+func (p *patternList) String() string { return strings.Join(*p, " ") }
+
+func (p *patternList) Set(s string) error {
+	*p = append(*p, s)
+	return nil
+}
+
+// jsonMatch es una coincidencia en -format jsonl (posiciones base 1).
+type jsonMatch struct {
+	Pattern  string `json:"pattern"`
+	Match    string `json:"match"`
+	Sequence string `json:"sequence"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+	Residues string `json:"residues"`
+	Gaps     []int  `json:"gaps"`
+}
+
+func main() {
+	var patterns patternList
+	flag.Var(&patterns, "p", "patrón PROSITE a buscar, ej. C-x(2,4)-C (se puede repetir)")
+	inputFile := flag.String("f", "", "archivo con las secuencias: una por línea, FASTA o Segmentos.json")
+	csvFile := flag.String("csv", "", "CSV de batchcompare (-csv) del que se toman los patrones de la primera columna")
+	outputFile := flag.String("o", "", "archivo de salida (opcional, por defecto stdout)")
+	format := flag.String("format", "tsv", "formato de salida: tsv o jsonl (una línea por coincidencia)")
+	match := flag.String("match", gaps.MatchDefault, "semántica de coincidencia: default, strict (gaps solo con minúsculas), relaxed (residuos en cualquier caso) o interaction (los gaps cuentan solo mayúsculas)")
+	maxMatches := flag.Int("max-matches", 1000, "máximo de coincidencias a reportar por patrón y secuencia (0 = sin límite)")
+	ligand := flag.String("ligand", "", "Segmentos.json: solo ligandos cuyo código empieza con este prefijo (ej. ZN)")
+	proteins := flag.String("proteins", "", "Segmentos.json: lista de proteínas separadas por coma (ej. 1a1f,1tf3)")
+	chains := flag.String("chain", "", "Segmentos.json: cadenas del ligando separadas por coma (ej. A,B)")
+	flag.Parse()
+
+	if *inputFile == "" || (len(patterns) == 0 && *csvFile == "") {
+		fmt.Fprintf(os.Stderr, "Uso: %s -f <archivo_secuencias> (-p <patrón> ... | -csv <resultados.csv>) [-o <archivo_salida>]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nOpciones:\n")
+		fmt.Fprintf(os.Stderr, "  -f <archivo>      Archivo de secuencias (una por línea, FASTA o Segmentos.json) [REQUERIDO]\n")
+		fmt.Fprintf(os.Stderr, "  -p <patrón>       Patrón PROSITE a buscar, ej. 'C-x(2,4)-C'; se puede repetir\n")
+		fmt.Fprintf(os.Stderr, "  -csv <archivo>    Toma los patrones del CSV generado por batchcompare\n")
+		fmt.Fprintf(os.Stderr, "  -o <archivo>      Archivo de salida (default: stdout)\n")
+		fmt.Fprintf(os.Stderr, "  -format <f>       tsv (default) o jsonl\n")
+		fmt.Fprintf(os.Stderr, "  -match <modo>     Semántica: default, strict, relaxed o interaction\n")
+		fmt.Fprintf(os.Stderr, "  -max-matches <n>  Coincidencias a reportar por patrón y secuencia (default: 1000, 0 = todas)\n")
+		fmt.Fprintf(os.Stderr, "  -ligand <código>  Segmentos.json: filtra por prefijo del código de ligando (ej. ZN)\n")
+		fmt.Fprintf(os.Stderr, "  -proteins <l>     Segmentos.json: filtra por proteínas (lista separada por comas)\n")
+		fmt.Fprintf(os.Stderr, "  -chain <l>        Segmentos.json: filtra por cadena del ligando (lista separada por comas)\n")
+		os.Exit(2)
+	}
+	if *format != "tsv" && *format != "jsonl" {
+		fmt.Fprintf(os.Stderr, "Formato desconocido %q (use tsv o jsonl)\n", *format)
+		os.Exit(2)
+	}
+	if err := gaps.CheckMatch(*match); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(2)
+	}
+	if *maxMatches < 0 {
+		fmt.Fprintf(os.Stderr, "-max-matches no puede ser negativo\n")
+		os.Exit(2)
+	}
+
+	// Compilar los patrones: los de -p son errores de uso, los del CSV de datos
+	var matchers []*scan.Matcher
+	seen := make(map[string]bool)
+	add := func(m *scan.Matcher) {
+		if !seen[m.String()] {
+			seen[m.String()] = true
+			matchers = append(matchers, m)
+		}
+	}
+	for _, p := range patterns {
+		m, err := scan.CompileMatch(p, *match)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(2)
+		}
+		add(m)
+	}
+	if *csvFile != "" {
+		fromCSV, err := readCSVPatterns(*csvFile, *match)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error al leer el CSV: %v\n", err)
+			os.Exit(1)
+		}
+		for _, m := range fromCSV {
+			add(m)
+		}
+	}
+
+	records, inputFormat, err := seqio.ReadFile(*inputFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error al leer el archivo: %v\n", err)
+		os.Exit(1)
+	}
+	filter := seqio.Filter{Ligand: *ligand, Proteins: seqio.SplitList(*proteins), Chains: seqio.SplitList(*chains)}
+	if !filter.Empty() {
+		if inputFormat != seqio.FormatSegments {
+			fmt.Fprintf(os.Stderr, "Los filtros -ligand, -proteins y -chain solo se aplican a archivos Segmentos.json\n")
+			os.Exit(2)
+		}
+		total := len(records)
+		records = filter.Apply(records)
+		fmt.Fprintf(os.Stderr, "Filtro de segmentos: %d de %d seleccionados\n", len(records), total)
+	}
+
+	var out io.Writer = os.Stdout
+	if *outputFile != "" {
+		file, err := os.Create(*outputFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error al crear archivo de salida: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		out = file
+	}
+	w := bufio.NewWriter(out)
+
+	if *match != gaps.MatchDefault {
+		fmt.Fprintf(os.Stderr, "Semántica de coincidencia: %s\n", *match)
+	}
+	if *format == "tsv" {
+		fmt.Fprintln(w, "Patrón\tSecuencia\tInicio\tFin\tResiduos\tGaps")
+	}
+	enc := json.NewEncoder(w)
+	for _, m := range matchers {
+		found, support, truncated := 0, 0, 0
+		for _, rec := range records {
+			matches, more := m.FindAll(rec.Seq, *maxMatches)
+			if len(matches) > 0 {
+				support++
+			}
+			if more {
+				truncated++
+			}
+			found += len(matches)
+			for _, mt := range matches {
+				// Posiciones base 1, con el final incluido
+				if *format == "jsonl" {
+					enc.Encode(jsonMatch{
+						Pattern:  m.String(),
+						Match:    m.Semantics(),
+						Sequence: rec.ID,
+						Start:    mt.Start + 1,
+						End:      mt.End,
+						Residues: mt.Residues,
+						Gaps:     append([]int{}, mt.Gaps...),
+					})
+					continue
+				}
+				fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\n", m, rec.ID, mt.Start+1, mt.End, mt.Residues, joinInts(mt.Gaps))
+			}
+		}
+
+		fmt.Fprintf(os.Stderr, "%s: %d coincidencias en %d de %d secuencias (%.2f%%)\n",
+			m, found, support, len(records), percent(support, len(records)))
+		if truncated > 0 {
+			fmt.Fprintf(os.Stderr, "  %d secuencias con más de -max-matches %d coincidencias (se reportan las primeras)\n", truncated, *maxMatches)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error al escribir la salida: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// readCSVPatterns compila los patrones de la primera columna de un CSV de
+// batchcompare, saltando el encabezado.
+func readCSVPatterns(filename, match string) ([]*scan.Matcher, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var out []*scan.Matcher
+	for i, row := range rows {
+		if len(row) == 0 || row[0] == "" || (i == 0 && row[0] == "Patrón") {
+			continue
+		}
+		m, err := scan.CompileMatch(row[0], match)
+		if err != nil {
+			return nil, fmt.Errorf("línea %d: %v", i+1, err)
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+func joinInts(vals []int) string {
+	parts := make([]string, len(vals))
+	for i, v := range vals {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
+
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total) * 100
+}
@@ -1,28 +1,34 @@
 package main
 
 import (
-	"encoding/json"
+	"bufio"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
-)
+	"time"
 
-// Segmentos representa la estructura del JSON generado por Interactions.py
-// map[protein_name]map[ligand_id][]string
-type Segmentos map[string]map[string][]string
+	"github.com/lucckkas/patternfinder/internal/cache"
+	"github.com/lucckkas/patternfinder/internal/progress"
+	"github.com/lucckkas/patternfinder/internal/seqio"
+)
 
 func main() {
 	// Flags
 	cifDir := flag.String("d", "./cifs", "directorio con archivos .cif")
-	ligand := flag.String("l", "ZN", "código del ligando a analizar (ej: ZN, MG, CA)")
+	ligand := flag.String("l", "ZN", "código del ligando a analizar, como prefijo (ej: ZN, MG, CA; ZN incluye también ZNH)")
 	pythonScript := flag.String("py", "./Interactions.py", "ruta a Interactions.py")
 	batchcompare := flag.String("b", "./build/batchcompare", "ruta al ejecutable batchcompare")
 	outputCSV := flag.String("o", "resultados.csv", "archivo CSV de salida")
 	workers := flag.Int("w", 6, "número de workers para batchcompare")
 	distance := flag.Float64("dist", 4.0, "distancia de interacción en Å")
+	noCache := flag.Bool("no-cache", false, "no usar el caché de resultados por par de batchcompare")
+	cacheDir := flag.String("cache-dir", cache.DefaultDir(), "directorio del caché de resultados por par")
+	cacheMB := flag.Int("cache-size", cache.DefaultMaxBytes/(1024*1024), "tamaño máximo del caché en MB (0 = sin límite)")
 	flag.Parse()
 
 	fmt.Println("=== Pipeline Completo: CIF → Interactions → BatchCompare ===")
@@ -78,57 +84,40 @@ func main() {
 
 	// 5. Leer el JSON generado
 	fmt.Println("\n=== Paso 2: Lectura de segmentos ===")
-	jsonData, err := os.ReadFile(segmentosFile)
+	records, _, err := seqio.ReadFile(segmentosFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error al leer %s: %v\n", segmentosFile, err)
 		os.Exit(1)
 	}
 
-	var segmentos Segmentos
-	if err := json.Unmarshal(jsonData, &segmentos); err != nil {
-		fmt.Fprintf(os.Stderr, "Error al parsear JSON: %v\n", err)
-		os.Exit(1)
-	}
-
-	// 6. Extraer secuencias del ligando especificado
-	var sequences []string
+	// 6. Contar los segmentos del ligando especificado con el mismo filtro
+	// que aplica batchcompare -ligand (prefijo del código, ver seqio.Filter)
 	ligandUpper := strings.ToUpper(*ligand)
+	records = seqio.Filter{Ligand: ligandUpper}.Apply(records)
+	total := len(records)
 
-	for proteinName, ligands := range segmentos {
-		for ligandID, seqs := range ligands {
-			// Verificar si el ligandID contiene el código del ligando
-			if strings.HasPrefix(ligandID, ligandUpper+"_") {
-				fmt.Printf("Proteína %s, Ligando %s: %d segmentos\n", proteinName, ligandID, len(seqs))
-				sequences = append(sequences, seqs...)
-			}
-		}
+	perLigand := make(map[string]int) // "proteína\x00ligando" -> segmentos
+	for _, r := range records {
+		perLigand[r.Protein+"\x00"+r.Ligand]++
 	}
-
-	if len(sequences) == 0 {
-		fmt.Fprintf(os.Stderr, "No se encontraron segmentos para el ligando %s\n", *ligand)
-		os.Exit(1)
+	keys := make([]string, 0, len(perLigand))
+	for k := range perLigand {
+		keys = append(keys, k)
 	}
-
-	fmt.Printf("\nTotal de secuencias extraídas: %d\n", len(sequences))
-
-	// 7. Guardar secuencias en archivo temporal
-	seqFile := "temp_sequences.txt"
-	// defer os.Remove(seqFile)
-
-	f, err := os.Create(seqFile)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error al crear archivo de secuencias: %v\n", err)
-		os.Exit(1)
+	sort.Strings(keys)
+	for _, k := range keys {
+		protein, ligandID, _ := strings.Cut(k, "\x00")
+		fmt.Printf("Proteína %s, Ligando %s: %d segmentos\n", protein, ligandID, perLigand[k])
 	}
 
-	for _, seq := range sequences {
-		fmt.Fprintln(f, seq)
+	if total == 0 {
+		fmt.Fprintf(os.Stderr, "No se encontraron segmentos para el ligando %s\n", *ligand)
+		os.Exit(1)
 	}
-	f.Close()
 
-	fmt.Printf("Secuencias guardadas en %s\n", seqFile)
+	fmt.Printf("\nTotal de segmentos del ligando %s: %d\n", ligandUpper, total)
 
-	// 8. Ejecutar batchcompare
+	// 7. Ejecutar batchcompare
 	fmt.Println("\n=== Paso 3: Comparación con BatchCompare ===")
 
 	absPath, err := filepath.Abs(*batchcompare)
@@ -142,16 +131,33 @@ func main() {
 		os.Exit(1)
 	}
 
-	cmd := exec.Command(absPath,
-		"-f", seqFile,
+	args := []string{
+		"-f", segmentosFile,
+		"-ligand", ligandUpper,
 		"-csv", *outputCSV,
 		"-w", fmt.Sprintf("%d", *workers),
-	)
+		"-progress", progress.ModeJSON,
+		"-cache-dir", *cacheDir,
+		"-cache-size", fmt.Sprintf("%d", *cacheMB),
+	}
+	if *noCache {
+		args = append(args, "-no-cache")
+	}
+	cmd := exec.Command(absPath, args...)
 
 	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error al ejecutar batchcompare: %v\n", err)
+		os.Exit(1)
+	}
 
-	if err := cmd.Run(); err != nil {
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error al ejecutar batchcompare: %v\n", err)
+		os.Exit(1)
+	}
+	relayProgress(stderr)
+	if err := cmd.Wait(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error al ejecutar batchcompare: %v\n", err)
 		os.Exit(1)
 	}
@@ -159,3 +165,29 @@ func main() {
 	fmt.Printf("\n=== Pipeline completado ===\n")
 	fmt.Printf("Resultados guardados en: %s\n", *outputCSV)
 }
+
+// relayProgress lee el stderr de batchcompare: los eventos de progreso se
+// muestran como una línea que se reescribe (si stderr es una terminal) o cada
+// 10 segundos; el resto se reenvía tal cual.
+func relayProgress(r io.Reader) {
+	tty := progress.IsTerminal(os.Stderr)
+	var last time.Time
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		e, ok := progress.ParseEvent(scanner.Bytes())
+		if !ok {
+			fmt.Fprintln(os.Stderr, scanner.Text())
+			continue
+		}
+		switch {
+		case tty:
+			fmt.Fprintf(os.Stderr, "\r  BatchCompare: %s\033[K", e)
+			if e.Event == progress.EventDone {
+				fmt.Fprintln(os.Stderr)
+			}
+		case e.Event == progress.EventDone || time.Since(last) >= 10*time.Second:
+			fmt.Fprintf(os.Stderr, "  BatchCompare: %s\n", e)
+			last = time.Now()
+		}
+	}
+}
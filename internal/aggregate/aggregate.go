@@ -1,8 +1,11 @@
 package aggregate
 
 import (
-	"fmt"
+	"math/big"
 	"sort"
+	"strings"
+
+	"github.com/lucckkas/patternfinder/internal/gaps"
 )
 
 // GapValues guarda el conjunto de valores discretos observados para un gap.
@@ -32,72 +35,84 @@ func PairMinValues(minsX, minsY []int) []GapValues {
 	return out
 }
 
-// helper: ¿la lista es un bloque contiguo de enteros?
-func isContiguous(sorted []int) bool {
-	if len(sorted) <= 1 {
-		return true
-	}
-	for i := 1; i < len(sorted); i++ {
-		if sorted[i] != sorted[i-1]+1 {
-			return false
-		}
+// SymbolFunc traduce un símbolo del patrón a su representación impresa,
+// ej. el representante de una clase a "[CH]". nil imprime la letra tal cual.
+type SymbolFunc func(b byte) string
+
+// symbol aplica sym, o devuelve la letra si sym es nil.
+func symbol(sym SymbolFunc, b byte) string {
+	if sym == nil {
+		return string(b)
 	}
-	return true
+	return sym(b)
 }
 
-// FormatPatternWithValues imprime P-x(...)-Q-x(...)-…
-// Regla:
+// FormatPatternWithValues imprime P-x(...)-Q-x(...)-… con la sintaxis de
+// PROSITE (ver gaps.GapElement), un patrón por cada rango contiguo de
+// valores de cada gap (ver gaps.GapRuns):
 // - si Values = {k}  => x(k)
-// - si Values cubren todos los enteros entre min y max => x(min,max)
-// - si no, x(v1|v2|...|vt)
-func FormatPatternWithValues(pattern string, sets []GapValues) string {
+// - si Values = {k..m} => x(k,m)
+// - si los valores no son contiguos, un patrón por rango: {2,5} da P-x(2)-Q
+// y P-x(5)-Q, porque PROSITE no tiene alternativas de longitud
+// - sin valores o solo 0 => residuos contiguos (P-Q)
+func FormatPatternWithValues(pattern string, sets []GapValues) []string {
+	return FormatPatternWithSymbols(pattern, sets, nil)
+}
+
+// FormatPatternWithSymbols es FormatPatternWithValues imprimiendo cada letra
+// del patrón con sym (ej. clases de equivalencia como [CH]-x(2)-C).
+func FormatPatternWithSymbols(pattern string, sets []GapValues, sym SymbolFunc) []string {
 	if len(pattern) == 0 {
-		return ""
+		return nil
 	}
-	out := make([]byte, 0, len(pattern)*4)
+	out := []string{""}
 	for i := 0; i < len(pattern); i++ {
-		out = append(out, pattern[i])
+		letter := symbol(sym, pattern[i])
+		seps := []string{""}
 		if i+1 < len(pattern) && i < len(sets) {
 			vals := append([]int(nil), sets[i].Values...)
 			sort.Ints(vals)
-			switch len(vals) {
-			case 0:
-				out = append(out, []byte("-")...)
-			case 1:
-				out = append(out, []byte(fmt.Sprintf("-x(%d)-", vals[0]))...)
-			default:
-				if isContiguous(vals) {
-					out = append(out, []byte(fmt.Sprintf("-x(%d,%d)-", vals[0], vals[len(vals)-1]))...)
-				} else {
-					// listado explícito
-					str := "x("
-					for j, v := range vals {
-						if j > 0 {
-							str += "|"
-						}
-						str += fmt.Sprintf("%d", v)
-					}
-					str += ")"
-					out = append(out, []byte("-"+str+"-")...)
+			seps = []string{gapString(nil)}
+			if runs := gaps.GapRuns(vals); len(runs) > 0 {
+				seps = seps[:0]
+				for _, run := range runs {
+					seps = append(seps, gapString(run))
 				}
 			}
 		}
+		next := make([]string, 0, len(out)*len(seps))
+		for _, prefix := range out {
+			for _, sep := range seps {
+				next = append(next, prefix+letter+sep)
+			}
+		}
+		out = next
 	}
-	return string(out)
+	return out
 }
 func PairUnionSets(setsX, setsY []map[int]struct{}) []GapValues {
-	n := len(setsX)
-	if len(setsY) < n {
-		n = len(setsY)
+	return UnionSets(setsX, setsY)
+}
+
+// UnionSets generaliza PairUnionSets a cualquier cantidad de secuencias: por
+// cada gap, la unión de los valores posibles en todas ellas.
+func UnionSets(sets ...[]map[int]struct{}) []GapValues {
+	if len(sets) == 0 {
+		return nil
+	}
+	n := len(sets[0])
+	for _, s := range sets[1:] {
+		if len(s) < n {
+			n = len(s)
+		}
 	}
 	out := make([]GapValues, n)
 	for i := 0; i < n; i++ {
 		union := make(map[int]struct{})
-		for v := range setsX[i] {
-			union[v] = struct{}{}
-		}
-		for v := range setsY[i] {
-			union[v] = struct{}{}
+		for _, s := range sets {
+			for v := range s[i] {
+				union[v] = struct{}{}
+			}
 		}
 		// pasar a slice ordenado
 		vals := make([]int, 0, len(union))
@@ -114,6 +129,27 @@ func PairUnionSets(setsX, setsY []map[int]struct{}) []GapValues {
 	return out
 }
 
+// CountCombinations devuelve cuántas combinaciones generaría
+// ExpandPatternCombinations sin generarlas (puede superar int64).
+func CountCombinations(pattern string, gapValues []GapValues) *big.Int {
+	total := big.NewInt(1)
+	for i := 0; i < len(gapValues) && i < len(pattern)-1; i++ {
+		if n := len(gapValues[i].Values); n > 1 {
+			total.Mul(total, big.NewInt(int64(n)))
+		}
+	}
+	return total
+}
+
+// gapString imprime el separador entre dos letras: "-x(..)-" o "-" si no hay
+// gap (vals ordenados).
+func gapString(vals []int) string {
+	if gap, ok := gaps.GapElement(vals); ok {
+		return "-" + gap.String() + "-"
+	}
+	return "-"
+}
+
 // ExpandPatternCombinations genera todas las combinaciones posibles de patrones
 // a partir de un patrón base y sus valores de gaps.
 // Por ejemplo, si tenemos A con gaps [[2,4], [3,5]], genera:
@@ -122,13 +158,23 @@ func PairUnionSets(setsX, setsY []map[int]struct{}) []GapValues {
 // - A-x(4)-B-x(3)-C
 // - A-x(4)-B-x(5)-C
 func ExpandPatternCombinations(pattern string, gapValues []GapValues) []string {
+	return ExpandPatternCombinationsWithSymbols(pattern, gapValues, nil)
+}
+
+// ExpandPatternCombinationsWithSymbols es ExpandPatternCombinations
+// imprimiendo cada letra del patrón con sym.
+func ExpandPatternCombinationsWithSymbols(pattern string, gapValues []GapValues, sym SymbolFunc) []string {
 	if len(pattern) == 0 {
 		return []string{""}
 	}
 
 	// Si no hay gaps o el patrón tiene solo una letra, retornar el patrón solo
 	if len(pattern) <= 1 || len(gapValues) == 0 {
-		return []string{pattern}
+		var b strings.Builder
+		for i := 0; i < len(pattern); i++ {
+			b.WriteString(symbol(sym, pattern[i]))
+		}
+		return []string{b.String()}
 	}
 
 	// Calcular el número total de combinaciones
@@ -161,15 +207,14 @@ func ExpandPatternCombinations(pattern string, gapValues []GapValues) []string {
 		}
 
 		// Agregar la letra actual
-		current += string(pattern[pos])
+		current += symbol(sym, pattern[pos])
 
 		// Si no es la última letra, agregar el gap
 		if pos+1 < len(pattern) {
 			if gapIndex < len(gapValues) && len(gapValues[gapIndex].Values) > 0 {
 				// Probar con cada valor posible del gap
 				for _, val := range gapValues[gapIndex].Values {
-					gapStr := fmt.Sprintf("-x(%d)-", val)
-					generate(pos+1, current+gapStr, gapIndex+1)
+					generate(pos+1, current+gapString([]int{val}), gapIndex+1)
 				}
 			} else {
 				// Si no hay valores para este gap, usar "-"
@@ -0,0 +1,260 @@
+// Package align implementa alineamiento con puntaje (Needleman–Wunsch /
+// Smith–Waterman con gaps afines de Gotoh) usando matrices de sustitución,
+// como alternativa al LCS puro para obtener motivos conservados.
+package align
+
+import "math"
+
+// Valores por defecto de penalización de gaps (abrir = primer residuo del
+// gap, extender = cada residuo adicional).
+const (
+	DefaultGapOpen   = 10
+	DefaultGapExtend = 1
+	// DefaultMaxAlignments limita la enumeración de alineamientos co-óptimos.
+	DefaultMaxAlignments = 100
+)
+
+// negInf representa un estado inalcanzable sin riesgo de desborde al restar.
+const negInf = math.MinInt32
+
+// Options configura el alineamiento.
+type Options struct {
+	Matrix        *Matrix // nil = BLOSUM62
+	GapOpen       int     // penalización del primer residuo de un gap (positiva)
+	GapExtend     int     // penalización de cada residuo adicional (positiva)
+	Local         bool    // Smith–Waterman en vez de Needleman–Wunsch
+	MaxAlignments int     // máximo de alineamientos co-óptimos (0 = DefaultMaxAlignments)
+}
+
+// Column es una columna del alineamiento: índices en sec1 (I) y sec2 (J),
+// con -1 del lado que tiene un gap.
+type Column struct {
+	I, J int
+}
+
+// Alignment es un alineamiento óptimo entre sec1 y sec2.
+type Alignment struct {
+	Score    int
+	AlignedX string // sec1 con '-' en los gaps
+	AlignedY string // sec2 con '-' en los gaps
+	Columns  []Column
+}
+
+// Identities devuelve las columnas donde x[I] == y[J] y las letras que
+// forman, es decir, una subsecuencia común comparable a una LCS. x e y son
+// las cadenas a comparar en esos índices (por ejemplo, traducidas a clases).
+func (a Alignment) Identities(x, y string) (string, []Column) {
+	var letters []byte
+	var cols []Column
+	for _, c := range a.Columns {
+		if c.I >= 0 && c.J >= 0 && x[c.I] == y[c.J] {
+			letters = append(letters, x[c.I])
+			cols = append(cols, c)
+		}
+	}
+	return string(letters), cols
+}
+
+// estados de Gotoh
+const (
+	stM = iota // sec1[i-1] alineado con sec2[j-1]
+	stX        // sec1[i-1] contra gap
+	stY        // gap contra sec2[j-1]
+)
+
+// tables guarda las tres matrices de Gotoh.
+type tables struct {
+	m, x, y [][]int
+}
+
+func newTable(n, m int) [][]int {
+	t := make([][]int, n+1)
+	for i := range t {
+		t[i] = make([]int, m+1)
+		for j := range t[i] {
+			t[i][j] = negInf
+		}
+	}
+	return t
+}
+
+func max3(a, b, c int) int {
+	return max(a, max(b, c))
+}
+
+// sub resta una penalización manteniendo negInf como piso.
+func sub(v, p int) int {
+	if v == negInf {
+		return negInf
+	}
+	return v - p
+}
+
+// Align calcula el puntaje óptimo y enumera hasta MaxAlignments
+// alineamientos co-óptimos distintos.
+func Align(sec1, sec2 string, opts Options) (int, []Alignment) {
+	if opts.Matrix == nil {
+		opts.Matrix = BLOSUM62()
+	}
+	if opts.MaxAlignments <= 0 {
+		opts.MaxAlignments = DefaultMaxAlignments
+	}
+	n, m := len(sec1), len(sec2)
+	t := tables{m: newTable(n, m), x: newTable(n, m), y: newTable(n, m)}
+	open, ext := opts.GapOpen, opts.GapExtend
+
+	t.m[0][0] = 0
+	for i := 0; i <= n; i++ {
+		for j := 0; j <= m; j++ {
+			if i == 0 && j == 0 {
+				continue
+			}
+			if i > 0 && j > 0 {
+				prev := max3(t.m[i-1][j-1], t.x[i-1][j-1], t.y[i-1][j-1])
+				if opts.Local && prev < 0 {
+					prev = 0 // el alineamiento local puede empezar aquí
+				}
+				if prev != negInf {
+					t.m[i][j] = prev + opts.Matrix.Score(sec1[i-1], sec2[j-1])
+				}
+			}
+			if i > 0 {
+				t.x[i][j] = max3(sub(t.m[i-1][j], open), sub(t.x[i-1][j], ext), sub(t.y[i-1][j], open))
+			}
+			if j > 0 {
+				t.y[i][j] = max3(sub(t.m[i][j-1], open), sub(t.y[i][j-1], ext), sub(t.x[i][j-1], open))
+			}
+		}
+	}
+
+	// Puntaje óptimo y celdas donde terminan los alineamientos
+	type end struct{ st, i, j int }
+	var ends []end
+	best := negInf
+	if opts.Local {
+		best = 0
+		for i := 1; i <= n; i++ {
+			for j := 1; j <= m; j++ {
+				if v := t.m[i][j]; v > best {
+					best, ends = v, []end{{stM, i, j}}
+				} else if v == best && v > 0 {
+					ends = append(ends, end{stM, i, j})
+				}
+			}
+		}
+		if best == 0 {
+			return 0, nil
+		}
+	} else {
+		if n == 0 && m == 0 {
+			return 0, []Alignment{{}}
+		}
+		best = max3(t.m[n][m], t.x[n][m], t.y[n][m])
+		for _, st := range []int{stM, stX, stY} {
+			if t.get(st, n, m) == best {
+				ends = append(ends, end{st, n, m})
+			}
+		}
+	}
+
+	var out []Alignment
+	for _, e := range ends {
+		if len(out) >= opts.MaxAlignments {
+			break
+		}
+		out = t.traceback(sec1, sec2, opts, e.st, e.i, e.j, best, out)
+	}
+	return best, out
+}
+
+func (t tables) get(st, i, j int) int {
+	switch st {
+	case stM:
+		return t.m[i][j]
+	case stX:
+		return t.x[i][j]
+	default:
+		return t.y[i][j]
+	}
+}
+
+// traceback enumera (DFS) todos los caminos óptimos que terminan en el
+// estado (st, i, j), hasta completar MaxAlignments alineamientos en out.
+func (t tables) traceback(sec1, sec2 string, opts Options, st, i, j, score int, out []Alignment) []Alignment {
+	open, ext := opts.GapOpen, opts.GapExtend
+	var path []Column // columnas en orden inverso
+
+	var walk func(st, i, j int)
+	walk = func(st, i, j int) {
+		if len(out) >= opts.MaxAlignments {
+			return
+		}
+		v := t.get(st, i, j)
+
+		// Fin del camino: origen (global) o inicio del alineamiento local
+		if st == stM && i == 0 && j == 0 {
+			out = append(out, build(sec1, sec2, path, score))
+			return
+		}
+
+		switch st {
+		case stM:
+			path = append(path, Column{I: i - 1, J: j - 1})
+			prev := v - opts.Matrix.Score(sec1[i-1], sec2[j-1])
+			if opts.Local && prev == 0 {
+				out = append(out, build(sec1, sec2, path, score))
+			} else {
+				for _, p := range []int{stM, stX, stY} {
+					if t.get(p, i-1, j-1) == prev && prev != negInf {
+						walk(p, i-1, j-1)
+					}
+				}
+			}
+		case stX:
+			path = append(path, Column{I: i - 1, J: -1})
+			for _, p := range []int{stM, stX, stY} {
+				pen := open
+				if p == stX {
+					pen = ext
+				}
+				if pv := t.get(p, i-1, j); pv != negInf && pv-pen == v {
+					walk(p, i-1, j)
+				}
+			}
+		case stY:
+			path = append(path, Column{I: -1, J: j - 1})
+			for _, p := range []int{stM, stX, stY} {
+				pen := open
+				if p == stY {
+					pen = ext
+				}
+				if pv := t.get(p, i, j-1); pv != negInf && pv-pen == v {
+					walk(p, i, j-1)
+				}
+			}
+		}
+		path = path[:len(path)-1]
+	}
+
+	walk(st, i, j)
+	return out
+}
+
+// build arma un Alignment a partir de las columnas en orden inverso.
+func build(sec1, sec2 string, rev []Column, score int) Alignment {
+	cols := make([]Column, len(rev))
+	ax := make([]byte, len(rev))
+	ay := make([]byte, len(rev))
+	for k := range rev {
+		c := rev[len(rev)-1-k]
+		cols[k] = c
+		ax[k], ay[k] = '-', '-'
+		if c.I >= 0 {
+			ax[k] = sec1[c.I]
+		}
+		if c.J >= 0 {
+			ay[k] = sec2[c.J]
+		}
+	}
+	return Alignment{Score: score, AlignedX: string(ax), AlignedY: string(ay), Columns: cols}
+}
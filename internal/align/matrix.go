@@ -0,0 +1,169 @@
+package align
+
+import (
+	"bufio"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//go:embed matrices/BLOSUM62
+var blosum62 string
+
+// Matrix es una matriz de sustitución (BLOSUM, PAM, ...) indexada por letra.
+type Matrix struct {
+	Name    string
+	score   [256][256]int
+	known   [256]bool
+	unknown int // puntaje para letras que no están en la matriz
+}
+
+// BLOSUM62 devuelve la matriz BLOSUM62 incluida en el binario.
+func BLOSUM62() *Matrix {
+	m, err := ParseMatrix(strings.NewReader(blosum62))
+	if err != nil {
+		panic("align: BLOSUM62 embebida inválida: " + err.Error())
+	}
+	m.Name = "BLOSUM62"
+	return m
+}
+
+// LoadMatrix lee una matriz en formato NCBI desde un archivo.
+func LoadMatrix(path string) (*Matrix, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m, err := ParseMatrix(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	m.Name = path
+	return m, nil
+}
+
+// ParseMatrix lee una matriz en formato NCBI: líneas de comentario con '#',
+// una cabecera con las letras de las columnas y una fila por letra con su
+// puntaje contra cada columna. Las letras se tratan sin distinguir caso.
+func ParseMatrix(r io.Reader) (*Matrix, error) {
+	m := &Matrix{}
+	var cols []byte
+	haveRow := make(map[byte]bool)
+	minScore := 0
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+
+		// Cabecera: letras de las columnas
+		if cols == nil {
+			for _, f := range fields {
+				if len(f) != 1 {
+					return nil, fmt.Errorf("línea %d: cabecera inválida %q", lineNumber, f)
+				}
+				cols = append(cols, upper(f[0]))
+			}
+			continue
+		}
+
+		if len(fields[0]) != 1 {
+			return nil, fmt.Errorf("línea %d: etiqueta de fila inválida %q", lineNumber, fields[0])
+		}
+		if len(fields)-1 != len(cols) {
+			return nil, fmt.Errorf("línea %d: se esperaban %d puntajes, hay %d", lineNumber, len(cols), len(fields)-1)
+		}
+		row := upper(fields[0][0])
+		if haveRow[row] {
+			return nil, fmt.Errorf("línea %d: fila %c repetida", lineNumber, row)
+		}
+		haveRow[row] = true
+		for k, f := range fields[1:] {
+			v, err := strconv.Atoi(f)
+			if err != nil {
+				return nil, fmt.Errorf("línea %d: puntaje inválido %q", lineNumber, f)
+			}
+			m.set(row, cols[k], v)
+			if v < minScore {
+				minScore = v
+			}
+		}
+		m.known[row] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if cols == nil || len(haveRow) == 0 {
+		return nil, fmt.Errorf("matriz vacía")
+	}
+	for _, c := range cols {
+		if !haveRow[c] {
+			return nil, fmt.Errorf("falta la fila para %c", c)
+		}
+	}
+
+	// Letras que no están en la matriz puntúan con el mínimo
+	m.unknown = minScore
+	return m, nil
+}
+
+// set guarda el puntaje para ambos casos de las letras.
+func (m *Matrix) set(a, b byte, v int) {
+	for _, x := range []byte{a, lower(a)} {
+		for _, y := range []byte{b, lower(b)} {
+			m.score[x][y] = v
+		}
+	}
+}
+
+// Digest resume el contenido de la matriz (no su nombre): dos matrices con
+// los mismos puntajes tienen el mismo resumen.
+func (m *Matrix) Digest() string {
+	h := sha256.New()
+	for a := 0; a < 256; a++ {
+		if !m.known[a] {
+			continue
+		}
+		for b := 0; b < 256; b++ {
+			if m.known[b] {
+				fmt.Fprintf(h, "%c%c%d;", a, b, m.score[a][b])
+			}
+		}
+	}
+	fmt.Fprintf(h, "?%d", m.unknown)
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// Score devuelve el puntaje de sustituir a por b.
+func (m *Matrix) Score(a, b byte) int {
+	if !m.known[upper(a)] || !m.known[upper(b)] {
+		return m.unknown
+	}
+	return m.score[a][b]
+}
+
+func upper(b byte) byte {
+	if b >= 'a' && b <= 'z' {
+		return b - 'a' + 'A'
+	}
+	return b
+}
+
+func lower(b byte) byte {
+	if b >= 'A' && b <= 'Z' {
+		return b - 'A' + 'a'
+	}
+	return b
+}
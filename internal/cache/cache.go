@@ -0,0 +1,268 @@
+// Package cache guarda en disco resultados direccionados por contenido: la
+// clave es un hash de todo lo que determina el resultado (por ejemplo, ambas
+// secuencias de un par y las opciones de comparación), así corridas sobre
+// datos que se solapan reutilizan los pares ya calculados.
+//
+// Cada entrada es un archivo <dir>/<2 primeros caracteres>/<clave>. Se
+// escribe en un temporal y se renombra, así varios procesos pueden compartir
+// el directorio sin ver entradas a medias. Cuando el tamaño total supera el
+// límite se borran las entradas usadas hace más tiempo (la fecha de
+// modificación se actualiza en cada acierto). Solo cuentan y se borran los
+// archivos con esa forma: cualquier otro archivo del directorio se ignora.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMaxBytes es el límite de tamaño por defecto (512 MB).
+const DefaultMaxBytes = 512 * 1024 * 1024
+
+// tmpPrefix marca los archivos a medio escribir (no cuentan como entradas).
+const tmpPrefix = ".tmp-"
+
+// DefaultDir devuelve el directorio por defecto: <caché del usuario>/patternfinder
+// (ej. ~/.cache/patternfinder), o uno dentro del directorio temporal si el
+// sistema no define uno.
+func DefaultDir() string {
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "patternfinder")
+	}
+	return filepath.Join(os.TempDir(), "patternfinder-cache")
+}
+
+// Key resume las partes en una clave (hash SHA-256 en hexadecimal).
+func Key(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		io.WriteString(h, p)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Cache es un directorio de entradas con un límite de tamaño. Un *Cache nil
+// es válido: nunca acierta y no guarda nada (-no-cache).
+type Cache struct {
+	dir      string
+	maxBytes int64 // <= 0 = sin límite
+
+	mu   sync.Mutex
+	size int64 // tamaño estimado; se recalcula al desalojar
+
+	hits, misses atomic.Int64
+}
+
+// Open abre (creando si hace falta) el caché en dir con un límite de
+// maxBytes bytes (<= 0 = sin límite).
+func Open(dir string, maxBytes int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	c := &Cache{dir: filepath.Clean(dir), maxBytes: maxBytes}
+	entries, err := c.entries()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		c.size += e.size
+	}
+	return c, nil
+}
+
+// Dir devuelve el directorio del caché.
+func (c *Cache) Dir() string {
+	if c == nil {
+		return ""
+	}
+	return c.dir
+}
+
+// Hits y Misses cuentan los aciertos y fallos de Get en este proceso.
+func (c *Cache) Hits() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.hits.Load()
+}
+
+func (c *Cache) Misses() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.misses.Load()
+}
+
+// Size devuelve el tamaño estimado de las entradas en bytes.
+func (c *Cache) Size() int64 {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key)
+}
+
+// Get devuelve la entrada de key, si existe.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	// Marcar como usada recientemente para el desalojo
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return data, true
+}
+
+// Put guarda data bajo key. Las entradas de más de un cuarto del límite no se
+// guardan (desalojarían casi todo el caché).
+func (c *Cache) Put(key string, data []byte) error {
+	if c == nil || (c.maxBytes > 0 && int64(len(data)) > c.maxBytes/4) {
+		return nil
+	}
+	shard := filepath.Join(c.dir, key[:2])
+	if err := os.MkdirAll(shard, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(shard, tmpPrefix+"*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	path := c.path(key)
+	var old int64
+	if info, err := os.Stat(path); err == nil {
+		old = info.Size()
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.size += int64(len(data)) - old
+	if c.maxBytes > 0 && c.size > c.maxBytes {
+		return c.evict()
+	}
+	return nil
+}
+
+type entry struct {
+	path  string
+	size  int64
+	mtime time.Time
+}
+
+// isHex indica si s es hexadecimal en minúsculas, como lo escribe Key.
+func isHex(s string) bool {
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// isShard indica si name es un subdirectorio de entradas (2 caracteres hex).
+func isShard(name string) bool {
+	return len(name) == 2 && isHex(name)
+}
+
+// isEntry indica si name, dentro del subdirectorio shard, es una entrada
+// escrita por Put (clave SHA-256 en hex que empieza con shard).
+func isEntry(shard, name string) bool {
+	return len(name) == sha256.Size*2 && strings.HasPrefix(name, shard) && isHex(name)
+}
+
+// entries lista las entradas del directorio. Recorre solo <dir>/<shard>/ y
+// deja fuera todo archivo que no tenga la forma de una clave, así un -cache-dir
+// que apunte a un directorio con otros archivos nunca los desaloja.
+func (c *Cache) entries() ([]entry, error) {
+	var out []entry
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Otro proceso pudo borrar el archivo mientras se recorría
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if path == c.dir {
+			return nil
+		}
+		parent := filepath.Dir(path)
+		if d.IsDir() {
+			if parent != c.dir || !isShard(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Dir(parent) != c.dir || !isEntry(filepath.Base(parent), d.Name()) || !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		out = append(out, entry{path: path, size: info.Size(), mtime: info.ModTime()})
+		return nil
+	})
+	return out, err
+}
+
+// evict borra las entradas usadas hace más tiempo hasta bajar al 90% del
+// límite (con c.mu tomado). El tamaño se recalcula desde el disco porque
+// otros procesos pueden estar usando el mismo directorio.
+func (c *Cache) evict() error {
+	entries, err := c.entries()
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].mtime.Before(entries[j].mtime) })
+
+	c.size = 0
+	for _, e := range entries {
+		c.size += e.size
+	}
+	target := c.maxBytes / 10 * 9
+	for _, e := range entries {
+		if c.size <= target {
+			break
+		}
+		if err := os.Remove(e.path); err == nil || os.IsNotExist(err) {
+			c.size -= e.size
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package compare
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lucckkas/patternfinder/internal/cache"
+	"github.com/lucckkas/patternfinder/internal/gaps"
+	"github.com/lucckkas/patternfinder/internal/lcs"
+)
+
+// ResultVersion se incrementa cuando cambia el texto o el significado de un
+// resultado guardado, para no reutilizar entradas viejas (del caché o del
+// journal de batchcompare).
+const ResultVersion = "pair/v4"
+
+// Fingerprint resume las opciones que cambian el resultado de una comparación
+// o su texto. Sequential, Timeout y Limits.MaxTime no entran (no cambian un
+// resultado completo, y los resultados cortados por tiempo no se guardan),
+// salvo para indicar si la enumeración es incremental: en ese caso un par con
+// más LCS que MaxLCS se trunca en vez de rechazarse.
+func (o Options) Fingerprint() string {
+	mode := o.Mode
+	if mode == "" {
+		mode = ModeLCS
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "schema=%d;mode=%s;dp=%v;mem=%d;max-lcs=%d;sample=%v;seed=%d;limit=%d;max-comb=%d;classes=%s",
+		SchemaVersion, mode, o.KeepDP, o.MemBudget, o.MaxLCS, o.Sample, o.Seed, o.Limits.MaxCount, o.MaxComb, o.Classes)
+	match := o.Match
+	if match == "" {
+		match = gaps.MatchDefault
+	}
+	fmt.Fprintf(&b, ";match=%s;stream=%v", match, o.streaming())
+	if mode == ModeAlign {
+		name, digest := "", ""
+		if o.Align.Matrix != nil {
+			name, digest = o.Align.Matrix.Name, o.Align.Matrix.Digest()
+		}
+		fmt.Fprintf(&b, ";matrix=%s:%s;gap=%d/%d;local=%v;max-align=%d",
+			name, digest, o.Align.GapOpen, o.Align.GapExtend, o.Align.Local, o.Align.MaxAlignments)
+	}
+	return b.String()
+}
+
+// Cached es un resultado tal como se guarda en el caché: el texto de
+// WriteText y el Report.
+type Cached struct {
+	Text   string `json:"text"`
+	Report Report `json:"report"`
+}
+
+// complete indica si el resultado no depende del reloj ni de una
+// cancelación (y por lo tanto se puede reutilizar).
+func (r Result) complete() bool {
+	return !r.TimedOut && (r.StopReason == nil || errors.Is(r.StopReason, lcs.ErrMaxCount))
+}
+
+// PairCached es Pair con caché: si c tiene el resultado de seq1 y seq2 con
+// las mismas opciones lo devuelve (hit = true); si no, compara y lo guarda,
+// salvo que la comparación se haya cortado por tiempo o cancelación. El
+// caché es un atajo: si falla al guardar, el resultado se devuelve igual.
+func PairCached(ctx context.Context, c *cache.Cache, seq1, seq2 string, opts Options) (entry Cached, hit bool) {
+	key := cache.Key(ResultVersion, seq1, seq2, opts.Fingerprint())
+	if data, ok := c.Get(key); ok {
+		if json.Unmarshal(data, &entry) == nil && entry.Report.Schema == SchemaVersion {
+			return entry, true
+		}
+	}
+
+	r := Pair(ctx, seq1, seq2, opts)
+	var text strings.Builder
+	r.WriteText(&text)
+	entry = Cached{Text: text.String(), Report: r.Report()}
+	if r.complete() {
+		if data, err := json.Marshal(entry); err == nil {
+			c.Put(key, data)
+		}
+	}
+	return entry, false
+}
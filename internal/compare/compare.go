@@ -0,0 +1,293 @@
+// Package compare contiene la comparación de un par de secuencias
+// (LCS o alineamiento → gaps → agregación) que comparten patternfinder y
+// batchcompare. Pair devuelve un Result tipado; WriteText lo imprime con el
+// formato de texto de patternfinder.
+package compare
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/lucckkas/patternfinder/internal/aggregate"
+	"github.com/lucckkas/patternfinder/internal/align"
+	"github.com/lucckkas/patternfinder/internal/gaps"
+	"github.com/lucckkas/patternfinder/internal/lcs"
+	"github.com/lucckkas/patternfinder/internal/utils"
+)
+
+// Modos de comparación.
+const (
+	ModeLCS   = "lcs"
+	ModeAlign = "align"
+)
+
+// Options reúne la configuración de una comparación.
+type Options struct {
+	Mode       string        // ModeLCS (default) o ModeAlign
+	Sequential bool          // tabla DP y backtracking secuenciales
+	KeepDP     bool          // conservar la tabla DP en el resultado (para -dp)
+	MemBudget  int64         // bytes para la tabla DP; <= 0 = sin límite
+	MaxLCS     int           // máximo de LCS distintas; 0 = sin límite
+	Sample     bool          // muestrear MaxLCS LCS en vez de rechazar
+	Seed       int64         // semilla del muestreo
+	Limits     lcs.Limits    // enumeración incremental (MaxCount / MaxTime)
+	Timeout    time.Duration // tiempo máximo de toda la comparación; 0 = sin límite
+	MaxComb    int           // máximo de combinaciones a generar por patrón; 0 = sin límite
+	Classes    *lcs.Classes  // clases de residuos equivalentes (nil = ninguna)
+	Match      string        // semántica de los gaps (gaps.Match*); "" = gaps.MatchDefault
+	Align      align.Options
+}
+
+// DefaultOptions devuelve los valores por defecto de patternfinder.
+func DefaultOptions() Options {
+	return Options{
+		Mode:      ModeLCS,
+		Match:     gaps.MatchDefault,
+		MemBudget: 1024 * 1024 * 1024,
+		MaxLCS:    10000,
+		Seed:      1,
+		Align: align.Options{
+			Matrix:        align.BLOSUM62(),
+			GapOpen:       align.DefaultGapOpen,
+			GapExtend:     align.DefaultGapExtend,
+			MaxAlignments: align.DefaultMaxAlignments,
+		},
+	}
+}
+
+// alignTables es la cantidad de tablas de (n+1)×(m+1) de align.Align.
+const alignTables = 3
+
+var (
+	// ErrTimeout indica que la comparación superó Options.Timeout.
+	ErrTimeout = errors.New("se agotó el tiempo de la comparación")
+	// ErrAlignBudget indica que las tablas del alineamiento no caben en
+	// Options.MemBudget.
+	ErrAlignBudget = errors.New("las tablas del alineamiento exceden el presupuesto de memoria")
+)
+
+// Pattern es un patrón base (una LCS o motivo) con sus gaps y combinaciones.
+type Pattern struct {
+	Base         string                // en representantes de clase
+	Rendered     string                // Base con las clases entre corchetes
+	GapsOK       bool                  // false si no se pudieron calcular los gaps
+	Gaps         []aggregate.GapValues // unión de valores por gap
+	ResiduesX    string                // incrustación en sec1, ej. "C3-x(5)-H9"
+	ResiduesY    string
+	Combinations []string // patrones con un valor por gap, ej. C-x(2)-H
+	Omitted      *big.Int // combinaciones no generadas por superar MaxComb (nil si se generaron)
+}
+
+// Result es el resultado de comparar un par de secuencias.
+type Result struct {
+	Seq1, Seq2     string
+	Upper1, Upper2 string
+
+	NoUppercase bool    // alguna secuencia no tiene mayúsculas
+	Linear      bool    // la tabla no cabía en MemBudget: una sola LCS (Hirschberg)
+	TableBytes  int64   // tamaño estimado de la tabla DP
+	DP          [][]int // solo con KeepDP
+	Total       *big.Int
+	Sampled     bool  // se muestrearon MaxLCS de Total LCS
+	Rejected    bool  // más LCS que MaxLCS y sin muestreo
+	OverBudget  bool  // ModeAlign: las tablas del alineamiento no caben en MemBudget
+	Truncated   bool  // la enumeración se detuvo antes de terminar
+	StopReason  error // motivo del corte (ver lcs.Enumerator.Err)
+	TimedOut    bool  // se superó Timeout; los patrones son parciales
+	Dropped     int   // LCS descartadas sin calcular gaps por Timeout
+
+	AlignScore int
+	Alignments []align.Alignment
+
+	Patterns []Pattern
+
+	opts Options
+}
+
+// Pair compara seq1 y seq2. ctx interrumpe la enumeración incremental
+// (Limits) y el cálculo de gaps entre un patrón y el siguiente; el resultado
+// queda con Truncated y StopReason = ctx.Err(). Con Timeout, la
+// enumeración es siempre incremental y se corta al vencer el plazo, y el
+// cálculo de gaps se detiene entre un patrón y el siguiente: el resultado
+// queda con TimedOut y los patrones obtenidos hasta ese momento. Si hay más
+// LCS que MaxLCS, con Limits o Timeout no se rechaza el par (salvo que se
+// muestree con Sample): se enumeran hasta MaxLCS y el resultado queda con
+// Truncated. Las tablas DP y el alineamiento (acotados por MemBudget: si
+// las tablas del alineamiento no caben, el resultado queda con OverBudget) no
+// se interrumpen.
+func Pair(ctx context.Context, seq1, seq2 string, opts Options) Result {
+	if opts.Mode == "" {
+		opts.Mode = ModeLCS
+	}
+	if opts.Match == "" {
+		opts.Match = gaps.MatchDefault
+	}
+	parent := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	// expired indica si venció el plazo propio (no una cancelación de afuera)
+	expired := func() bool {
+		return opts.Timeout > 0 && ctx.Err() != nil && parent.Err() == nil
+	}
+	classes := opts.Classes
+	r := Result{Seq1: seq1, Seq2: seq2, opts: opts}
+
+	// Proyecciones a mayúsculas: fuente única para índices, residuos y distancias
+	px := utils.Project(seq1)
+	py := utils.Project(seq2)
+	r.Upper1, r.Upper2 = px.Upper, py.Upper
+	// Con clases, cada mayúscula se reemplaza por el representante de su grupo
+	// (las minúsculas no cambian, así índices y distancias se conservan). Los
+	// gaps se miden con Projection.Distance sobre la proyección de la
+	// secuencia traducida: la de px y py, salvo con gaps.MatchRelaxed, que
+	// pasa antes todo a mayúsculas
+	gx := utils.Project(classes.Translate(gaps.MatchSequence(seq1, opts.Match)))
+	gy := utils.Project(classes.Translate(gaps.MatchSequence(seq2, opts.Match)))
+	Ux, Uy := classes.Translate(px.Upper), classes.Translate(py.Upper)
+
+	if len(Ux) == 0 || len(Uy) == 0 {
+		r.NoUppercase = true
+		return r
+	}
+
+	var (
+		dp  [][]int
+		all []string
+	)
+	r.TableBytes = lcs.TableBytes(len(Ux), len(Uy))
+	if opts.Mode == ModeAlign {
+		// El alineamiento usa tres tablas completas y no tiene modo lineal:
+		// si no caben en el presupuesto se omite el par
+		r.TableBytes *= alignTables
+		if opts.MemBudget > 0 && r.TableBytes > opts.MemBudget {
+			r.OverBudget, r.StopReason = true, ErrAlignBudget
+			return r
+		}
+		// Alineamiento con puntaje: los motivos son las columnas idénticas
+		all = r.alignMotifs(Ux, Uy)
+	} else if !lcs.FitsInBudget(len(Ux), len(Uy), opts.MemBudget) {
+		// La tabla completa no cabe: memoria lineal, una sola LCS
+		r.Linear = true
+		if n, s := lcs.Hirschberg(Ux, Uy); n > 0 {
+			all = []string{s}
+		}
+	} else if opts.Sequential {
+		dp = lcs.DPTable(Ux, Uy)
+	} else {
+		dp = lcs.DPTableParallel(Ux, Uy)
+	}
+	if opts.KeepDP {
+		r.DP = dp
+	}
+
+	if dp != nil {
+		// Contar las LCS distintas antes de enumerarlas para no colgarse
+		// con entradas repetitivas
+		counter := lcs.NewCounter(Ux, Uy, dp)
+		r.Total = counter.Count()
+		within := opts.MaxLCS <= 0 || r.Total.Cmp(big.NewInt(int64(opts.MaxLCS))) <= 0
+
+		switch {
+		case opts.streaming() && (within || !opts.Sample):
+			// Con límites de enumeración se devuelven las primeras LCS en vez
+			// de rechazar el par; si hay más que MaxLCS, nunca más de MaxLCS
+			limits := opts.Limits
+			if !within && (limits.MaxCount <= 0 || limits.MaxCount > opts.MaxLCS) {
+				limits.MaxCount = opts.MaxLCS
+			}
+			enum := lcs.NewEnumerator(Ux, Uy, dp, limits)
+			all, _ = enum.Collect(ctx)
+			r.Truncated, r.StopReason = enum.Truncated(), enum.Err()
+			if r.Truncated && expired() {
+				r.TimedOut, r.StopReason = true, ErrTimeout
+			}
+		case within && opts.Sequential:
+			all = lcs.Backtracking(Ux, Uy, dp)
+		case within:
+			all = lcs.BacktrackingParallel(Ux, Uy, dp)
+		case opts.Sample:
+			all = counter.SampleDistinct(opts.MaxLCS, rand.New(rand.NewSource(opts.Seed)))
+			r.Sampled = true
+		default:
+			r.Rejected = true
+			return r
+		}
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		if len(all[i]) != len(all[j]) {
+			return len(all[i]) > len(all[j])
+		}
+		return all[i] < all[j]
+	})
+
+	for k, pat := range all {
+		if expired() {
+			r.TimedOut = true
+			r.Dropped = len(all) - k
+			break
+		}
+		if err := parent.Err(); err != nil {
+			// Cancelación de afuera (ej. Ctrl-C): resultado parcial
+			r.Truncated, r.StopReason = true, err
+			break
+		}
+		p := Pattern{Base: pat, Rendered: classes.Render(pat)}
+		setsX, okX := gaps.GapValues(gx, pat, opts.Match)
+		setsY, okY := gaps.GapValues(gy, pat, opts.Match)
+		if !okX || !okY {
+			r.Patterns = append(r.Patterns, p)
+			continue
+		}
+		p.GapsOK = true
+		p.Gaps = aggregate.PairUnionSets(setsX, setsY)
+
+		// Generar todas las combinaciones de patrones, salvo que sean demasiadas
+		if n := aggregate.CountCombinations(pat, p.Gaps); opts.MaxComb > 0 && n.Cmp(big.NewInt(int64(opts.MaxComb))) > 0 {
+			p.Omitted = n
+		} else if classes != nil {
+			p.Combinations = aggregate.ExpandPatternCombinationsWithSymbols(pat, p.Gaps, classes.Symbol)
+		} else {
+			p.Combinations = aggregate.ExpandPatternCombinations(pat, p.Gaps)
+		}
+
+		if al, ok := lcs.EmbedLCS(pat, Ux, Uy); ok {
+			p.ResiduesX = residues(px, al.IndicesX(), opts.Match)
+			p.ResiduesY = residues(py, al.IndicesY(), opts.Match)
+		}
+		r.Patterns = append(r.Patterns, p)
+	}
+	return r
+}
+
+// streaming indica si la enumeración es incremental (Limits o Timeout).
+func (o Options) streaming() bool {
+	return o.Limits.MaxCount > 0 || o.Limits.MaxTime > 0 || o.Timeout > 0
+}
+
+// alignMotifs alinea las mayúsculas originales con la matriz de sustitución,
+// guarda los alineamientos co-óptimos en r y devuelve los motivos distintos
+// formados por sus columnas idénticas en tx, ty (las mayúsculas traducidas a
+// clases, si las hay). Los motivos siguen el mismo camino de gaps y formato
+// que las LCS.
+func (r *Result) alignMotifs(tx, ty string) []string {
+	r.AlignScore, r.Alignments = align.Align(r.Upper1, r.Upper2, r.opts.Align)
+
+	seen := make(map[string]bool)
+	var motifs []string
+	for _, a := range r.Alignments {
+		motif, _ := a.Identities(tx, ty)
+		if motif != "" && !seen[motif] {
+			seen[motif] = true
+			motifs = append(motifs, motif)
+		}
+	}
+	return motifs
+}
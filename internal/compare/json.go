@@ -0,0 +1,159 @@
+package compare
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/lucckkas/patternfinder/internal/lcs"
+)
+
+// SchemaVersion es la versión del esquema JSON de Report. Se incrementa
+// cuando cambia el significado o se eliminan campos; los campos nuevos se
+// agregan sin cambiar la versión.
+const SchemaVersion = 1
+
+// Estados de una comparación en Report.Status. Solo StatusOK indica un
+// resultado completo (salvo el tope deliberado de -limit/-max-lcs, que se
+// informa con Truncated).
+const (
+	StatusOK          = "ok"           // hay patrones (o la búsqueda terminó sin LCS)
+	StatusNoUppercase = "no_uppercase" // alguna secuencia no tiene mayúsculas
+	StatusRejected    = "rejected"     // más LCS que -max-lcs y sin -sample, o alineamiento que no cabe en -mem
+	StatusTimeout     = "timeout"      // se superó Options.Timeout o Limits.MaxTime; resultados parciales
+	StatusCanceled    = "canceled"     // ctx cancelado (ej. Ctrl-C); resultados parciales
+)
+
+// Report es la forma serializable (JSON) de un Result.
+type Report struct {
+	Schema     int    `json:"schema"`
+	Seq1       string `json:"seq1"`
+	Seq2       string `json:"seq2"`
+	Upper1     string `json:"upper1"`
+	Upper2     string `json:"upper2"`
+	Mode       string `json:"mode"`
+	Classes    string `json:"classes,omitempty"`
+	Match      string `json:"match"` // semántica de los gaps (gaps.Match*)
+	Status     string `json:"status"`
+	Linear     bool   `json:"linear,omitempty"`
+	TotalLCS   string `json:"total_lcs,omitempty"` // entero decimal (puede superar int64)
+	Sampled    bool   `json:"sampled,omitempty"`
+	Truncated  bool   `json:"truncated,omitempty"`
+	StopReason string `json:"stop_reason,omitempty"`
+	Dropped    int    `json:"dropped,omitempty"` // LCS sin gaps calculados por timeout
+
+	AlignScore *int              `json:"align_score,omitempty"`
+	Alignments []AlignmentReport `json:"alignments,omitempty"`
+
+	Patterns []PatternReport `json:"patterns"`
+}
+
+// AlignmentReport es un alineamiento co-óptimo (-mode align).
+type AlignmentReport struct {
+	Aligned1 string `json:"aligned1"`
+	Aligned2 string `json:"aligned2"`
+}
+
+// PatternReport es un patrón base con sus gaps y combinaciones.
+type PatternReport struct {
+	LCS          string   `json:"lcs"`
+	Rendered     string   `json:"rendered"`
+	GapsOK       bool     `json:"gaps_ok"`
+	Gaps         [][]int  `json:"gaps"` // valores posibles de cada gap ([0] = residuos contiguos)
+	Residues1    string   `json:"residues1,omitempty"`
+	Residues2    string   `json:"residues2,omitempty"`
+	Combinations []string `json:"combinations"`
+	Omitted      string   `json:"combinations_omitted,omitempty"` // total no generado por -max-comb
+}
+
+// Status devuelve el estado de la comparación (ver Status*).
+func (r Result) Status() string {
+	switch {
+	case r.NoUppercase:
+		return StatusNoUppercase
+	case r.Rejected, r.OverBudget:
+		return StatusRejected
+	case r.TimedOut, errors.Is(r.StopReason, lcs.ErrMaxTime), errors.Is(r.StopReason, context.DeadlineExceeded):
+		return StatusTimeout
+	case errors.Is(r.StopReason, context.Canceled):
+		return StatusCanceled
+	}
+	return StatusOK
+}
+
+// Report arma la forma serializable del resultado.
+func (r Result) Report() Report {
+	rep := Report{
+		Schema:    SchemaVersion,
+		Seq1:      r.Seq1,
+		Seq2:      r.Seq2,
+		Upper1:    r.Upper1,
+		Upper2:    r.Upper2,
+		Mode:      r.opts.Mode,
+		Classes:   r.opts.Classes.String(),
+		Match:     r.opts.Match,
+		Status:    r.Status(),
+		Linear:    r.Linear,
+		Sampled:   r.Sampled,
+		Truncated: r.Truncated,
+		Dropped:   r.Dropped,
+		Patterns:  make([]PatternReport, 0, len(r.Patterns)),
+	}
+	if r.Total != nil {
+		rep.TotalLCS = r.Total.String()
+	}
+	if r.StopReason != nil {
+		rep.StopReason = r.StopReason.Error()
+	}
+	if r.opts.Mode == ModeAlign && !r.NoUppercase {
+		score := r.AlignScore
+		rep.AlignScore = &score
+		for _, a := range r.Alignments {
+			rep.Alignments = append(rep.Alignments, AlignmentReport{Aligned1: a.AlignedX, Aligned2: a.AlignedY})
+		}
+	}
+	for _, p := range r.Patterns {
+		pr := PatternReport{
+			LCS:          p.Base,
+			Rendered:     p.Rendered,
+			GapsOK:       p.GapsOK,
+			Gaps:         make([][]int, len(p.Gaps)),
+			Residues1:    p.ResiduesX,
+			Residues2:    p.ResiduesY,
+			Combinations: p.Combinations,
+		}
+		for i, g := range p.Gaps {
+			// PairUnionSets deja Values vacío cuando el único valor es 0
+			pr.Gaps[i] = g.Values
+			if len(pr.Gaps[i]) == 0 {
+				pr.Gaps[i] = []int{0}
+			}
+		}
+		if p.Omitted != nil {
+			pr.Omitted = p.Omitted.String()
+		}
+		if pr.Combinations == nil {
+			pr.Combinations = []string{}
+		}
+		rep.Patterns = append(rep.Patterns, pr)
+	}
+	return rep
+}
+
+// WriteJSON escribe el resultado como JSON: indentado, o en una sola línea
+// si compact es true (formato JSON Lines).
+func (r Result) WriteJSON(w io.Writer, compact bool) error {
+	return WriteReport(w, r.Report(), compact)
+}
+
+// WriteReport escribe rep como lo hace Result.WriteJSON (útil cuando solo se
+// tiene el Report, por ejemplo desde el caché).
+func WriteReport(w io.Writer, rep Report, compact bool) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if !compact {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(rep)
+}
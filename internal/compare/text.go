@@ -0,0 +1,148 @@
+package compare
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lucckkas/patternfinder/internal/gaps"
+	"github.com/lucckkas/patternfinder/internal/lcs"
+	"github.com/lucckkas/patternfinder/internal/utils"
+)
+
+// matchDescription resume cada semántica de coincidencia no default.
+var matchDescription = map[string]string{
+	gaps.MatchStrict:      "los gaps solo pueden contener minúsculas",
+	gaps.MatchRelaxed:     "los residuos del patrón coinciden en cualquier caso",
+	gaps.MatchInteraction: "los gaps cuentan solo mayúsculas",
+}
+
+// WriteText escribe el resultado con el formato de texto de patternfinder.
+func (r Result) WriteText(w io.Writer) {
+	opts := r.opts
+	classes := opts.Classes
+
+	if r.NoUppercase {
+		fmt.Fprintln(w, "No hay mayúsculas en alguna secuencia; no existe LCS.")
+		return
+	}
+
+	if r.OverBudget {
+		fmt.Fprintf(w, "Las tablas del alineamiento (%d MB) exceden el presupuesto de %d MB; aumente -mem para alinear este par.\n",
+			r.TableBytes/(1024*1024), opts.MemBudget/(1024*1024))
+		return
+	}
+	if opts.Mode == ModeAlign {
+		kind := "global"
+		if opts.Align.Local {
+			kind = "local"
+		}
+		name := "BLOSUM62"
+		if opts.Align.Matrix != nil {
+			name = opts.Align.Matrix.Name
+		}
+		fmt.Fprintf(w, "Alineamiento %s (%s, gap %d/%d): puntaje %d, %d co-óptimos\n",
+			kind, name, opts.Align.GapOpen, opts.Align.GapExtend, r.AlignScore, len(r.Alignments))
+		for k, a := range r.Alignments {
+			fmt.Fprintf(w, "  <%d> %s\n      %s\n", k+1, a.AlignedX, a.AlignedY)
+		}
+		fmt.Fprintln(w)
+	}
+	if r.Linear {
+		fmt.Fprintf(w, "Tabla DP de %d MB excede el presupuesto de %d MB; usando modo lineal (Hirschberg), se reporta una sola LCS.\n\n",
+			r.TableBytes/(1024*1024), opts.MemBudget/(1024*1024))
+	}
+	if r.DP != nil {
+		fmt.Fprintln(w, "Matriz LCS (longitudes):")
+		lcs.FprintDP(w, classes.Translate(r.Upper1), classes.Translate(r.Upper2), r.DP)
+	}
+
+	if r.Total != nil {
+		fmt.Fprintf(w, "%s LCS encontradas\n", r.Total)
+		switch {
+		case r.Rejected:
+			fmt.Fprintf(w, "Hay más LCS distintas que el límite -max-lcs %d; use -sample para muestrear o aumente el límite.\n", opts.MaxLCS)
+			return
+		case r.Sampled:
+			fmt.Fprintf(w, "Se muestrearon %d de %s LCS distintas (límite -max-lcs %d).\n", len(r.Patterns), r.Total, opts.MaxLCS)
+		case r.Truncated:
+			fmt.Fprintf(w, "[TRUNCADO] enumeración detenida (%v); se muestran %d LCS.\n", r.StopReason, len(r.Patterns))
+		}
+		fmt.Fprintln(w)
+	}
+	if r.Dropped > 0 {
+		fmt.Fprintf(w, "[TIEMPO AGOTADO] límite -timeout %v; se calcularon los gaps de %d de %d LCS.\n\n",
+			opts.Timeout, len(r.Patterns), len(r.Patterns)+r.Dropped)
+	}
+
+	if len(r.Patterns) == 0 {
+		fmt.Fprintln(w, "No se encontraron LCS.")
+		return
+	}
+
+	fmt.Fprintf(w, "Secuencia 1 (original): %s\n", r.Seq1)
+	fmt.Fprintf(w, "Secuencia 2 (original): %s\n", r.Seq2)
+	fmt.Fprintf(w, "Mayúsculas 1: %s\n", r.Upper1)
+	fmt.Fprintf(w, "Mayúsculas 2: %s\n\n", r.Upper2)
+	if opts.Match != gaps.MatchDefault {
+		fmt.Fprintf(w, "Semántica de coincidencia: %s (%s)\n\n", opts.Match, matchDescription[opts.Match])
+	}
+	if classes != nil {
+		fmt.Fprintf(w, "Clases: %s\n\n", classes)
+		rendered := make([]string, len(r.Patterns))
+		for i, p := range r.Patterns {
+			rendered[i] = p.Rendered
+		}
+		fmt.Fprintf(w, "LCS: %v\n\n", rendered)
+	} else {
+		bases := make([]string, len(r.Patterns))
+		for i, p := range r.Patterns {
+			bases[i] = p.Base
+		}
+		fmt.Fprintf(w, "LCS: %v\n\n", bases)
+	}
+
+	for idx, p := range r.Patterns {
+		if !p.GapsOK {
+			fmt.Fprintf(w, "[%d] %s -> (no se pudo calcular gaps)\n", idx+1, p.Rendered)
+			continue
+		}
+		fmt.Fprintf(w, "[%d] Patrón base: %s | valores: %v\n", idx+1, p.Rendered, p.Gaps)
+		if p.ResiduesX != "" {
+			fmt.Fprintf(w, "    Residuos: sec1 %s | sec2 %s\n", p.ResiduesX, p.ResiduesY)
+		}
+		if p.Omitted != nil {
+			fmt.Fprintf(w, "    Combinaciones (%s): omitidas, superan -max-comb %d\n\n", p.Omitted, opts.MaxComb)
+			continue
+		}
+		fmt.Fprintf(w, "    Combinaciones (%d):\n", len(p.Combinations))
+		for i, comb := range p.Combinations {
+			fmt.Fprintf(w, "    [%d.%d] %s\n", idx+1, i+1, comb)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// residues formatea una incrustación (índices de mayúsculas) como residuos
+// numerados en la secuencia original (base 1) separados por su distancia,
+// ej. "C3-x(5)-H9". Con gaps.MatchInteraction la distancia cuenta solo
+// mayúsculas.
+func residues(p *utils.Projection, idx []int, match string) string {
+	gapsOrig := p.Gaps(idx)
+	out := make([]byte, 0, len(idx)*10)
+	for k, u := range idx {
+		if k > 0 {
+			g := gapsOrig[k-1]
+			if match == gaps.MatchInteraction {
+				g = u - idx[k-1] - 1
+			}
+			if g > 0 {
+				out = append(out, []byte(fmt.Sprintf("-x(%d)", g))...)
+			}
+			out = append(out, '-')
+		}
+		pos := p.OriginalPos(u)
+		out = append(out, p.Original[pos])
+		out = append(out, []byte(fmt.Sprintf("%d", pos+1))...)
+	}
+	return string(out)
+}
@@ -0,0 +1,112 @@
+package enrich
+
+import (
+	"math"
+	"sort"
+
+	"github.com/lucckkas/patternfinder/internal/scan"
+)
+
+// Contrast compara el soporte de un patrón en un conjunto positivo (ej.
+// segmentos que unen zinc) y uno negativo.
+type Contrast struct {
+	Pattern            string
+	Rank               int // 1 = el más discriminativo
+	Pos, Neg           int // secuencias con el patrón en cada conjunto
+	PosTotal, NegTotal int
+	SupportDiff        float64 // Pos/PosTotal - Neg/NegTotal
+	OddsRatio          float64 // con corrección de Haldane (+0.5) si alguna celda es 0
+	PValue             float64 // test exacto de Fisher, unilateral (más frecuente en positivos)
+}
+
+// Discriminate busca cada patrón en pos y neg y los ordena del más al menos
+// discriminativo: p-valor de Fisher creciente, luego diferencia de soporte y
+// odds ratio decrecientes.
+func Discriminate(pos, neg, patterns []string, match string) ([]Contrast, error) {
+	out := make([]Contrast, len(patterns))
+	for i, p := range patterns {
+		m, err := scan.CompileMatch(p, match)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = NewContrast(p, m.Support(pos), len(pos), m.Support(neg), len(neg))
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		switch {
+		case a.PValue != b.PValue:
+			return a.PValue < b.PValue
+		case a.SupportDiff != b.SupportDiff:
+			return a.SupportDiff > b.SupportDiff
+		case a.OddsRatio != b.OddsRatio:
+			return a.OddsRatio > b.OddsRatio
+		}
+		return a.Pattern < b.Pattern
+	})
+	for i := range out {
+		out[i].Rank = i + 1
+	}
+	return out, nil
+}
+
+// NewContrast calcula las medidas de un patrón presente en pos de posTotal
+// secuencias positivas y en neg de negTotal negativas.
+func NewContrast(pattern string, pos, posTotal, neg, negTotal int) Contrast {
+	c := Contrast{Pattern: pattern, Pos: pos, Neg: neg, PosTotal: posTotal, NegTotal: negTotal}
+	c.SupportDiff = fraction(pos, posTotal) - fraction(neg, negTotal)
+
+	// Tabla 2x2 (ver FisherGreater)
+	ta, tb := float64(pos), float64(posTotal-pos)
+	tc, td := float64(neg), float64(negTotal-neg)
+	if ta == 0 || tb == 0 || tc == 0 || td == 0 {
+		ta, tb, tc, td = ta+0.5, tb+0.5, tc+0.5, td+0.5
+	}
+	c.OddsRatio = (ta * td) / (tb * tc)
+	c.PValue = FisherGreater(pos, posTotal-pos, neg, negTotal-neg)
+	return c
+}
+
+func fraction(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+// FisherGreater es el test exacto de Fisher unilateral para la tabla
+//
+//	        con  sin
+//	pos      a    b
+//	neg      c    d
+//
+// la probabilidad, con los márgenes fijos, de que haya a o más positivos con
+// el patrón (distribución hipergeométrica).
+func FisherGreater(a, b, c, d int) float64 {
+	row := a + b // positivos
+	col := a + c // secuencias con el patrón
+	n := a + b + c + d
+	hi := row
+	if col < hi {
+		hi = col
+	}
+	logTotal := logChoose(n, col)
+	p := 0.0
+	for x := a; x <= hi; x++ {
+		p += math.Exp(logChoose(row, x) + logChoose(n-row, col-x) - logTotal)
+	}
+	if p > 1 {
+		p = 1
+	}
+	return p
+}
+
+// logChoose devuelve log C(n, k).
+func logChoose(n, k int) float64 {
+	if k < 0 || k > n {
+		return math.Inf(-1)
+	}
+	ln, _ := math.Lgamma(float64(n + 1))
+	lk, _ := math.Lgamma(float64(k + 1))
+	lnk, _ := math.Lgamma(float64(n - k + 1))
+	return ln - lk - lnk
+}
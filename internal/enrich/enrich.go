@@ -0,0 +1,202 @@
+// Package enrich estima si un patrón aparece en más secuencias de las que se
+// esperaría por azar. El soporte de un patrón es la cantidad de secuencias en
+// las que aparece (búsqueda con scan); el modelo nulo genera conjuntos de
+// secuencias al azar con la misma composición (permutaciones de cada
+// secuencia, o una cadena de Markov de orden 1 entrenada con todas) y mide
+// el soporte en cada uno. El p-valor es la fracción de conjuntos nulos con
+// soporte mayor o igual al observado, y el q-valor lo ajusta por la cantidad
+// de patrones (Benjamini-Hochberg).
+//
+// Discriminate, en cambio, compara el soporte en un conjunto positivo y uno
+// negativo (diferencia de soporte, odds ratio y test exacto de Fisher).
+package enrich
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/lucckkas/patternfinder/internal/gaps"
+	"github.com/lucckkas/patternfinder/internal/scan"
+)
+
+// Modelos nulos.
+const (
+	NullShuffle = "shuffle" // permutar los residuos de cada secuencia (conserva composición y caso)
+	NullMarkov  = "markov"  // cadena de Markov de orden 1 entrenada con todas las secuencias
+)
+
+// Options configura el análisis.
+type Options struct {
+	Null         string // NullShuffle (default) o NullMarkov
+	Permutations int    // conjuntos nulos a generar
+	Seed         int64  // semilla: mismos datos y semilla dan los mismos p-valores
+	Match        string // semántica de coincidencia (gaps.Match*)
+	Workers      int    // permutaciones en paralelo (<= 1 = secuencial)
+}
+
+// Result es la significancia de un patrón.
+type Result struct {
+	Pattern  string
+	Observed int     // secuencias en las que aparece el patrón
+	Expected float64 // soporte medio bajo el modelo nulo
+	PValue   float64 // (1 + nulos con soporte >= Observed) / (1 + Permutations)
+	QValue   float64 // p-valor ajustado por Benjamini-Hochberg
+}
+
+// CheckNull valida el nombre de un modelo nulo.
+func CheckNull(null string) error {
+	switch null {
+	case "", NullShuffle, NullMarkov:
+		return nil
+	}
+	return fmt.Errorf("modelo nulo desconocido %q (use shuffle o markov)", null)
+}
+
+// Analyze calcula la significancia de cada patrón (sintaxis PROSITE) en
+// seqs. Los resultados quedan en el orden de patterns. ctx permite cortar
+// entre permutaciones; en ese caso se devuelve ctx.Err().
+func Analyze(ctx context.Context, seqs, patterns []string, opts Options) ([]Result, error) {
+	if err := CheckNull(opts.Null); err != nil {
+		return nil, err
+	}
+	if opts.Permutations <= 0 {
+		return nil, fmt.Errorf("se necesita al menos una permutación")
+	}
+	matchers := make([]*scan.Matcher, len(patterns))
+	for i, p := range patterns {
+		m, err := scan.CompileMatch(p, opts.Match)
+		if err != nil {
+			return nil, err
+		}
+		matchers[i] = m
+	}
+
+	results := make([]Result, len(patterns))
+	for i, m := range matchers {
+		results[i] = Result{Pattern: patterns[i], Observed: m.Support(seqs)}
+	}
+
+	var markov *Markov
+	if opts.Null == NullMarkov {
+		markov = TrainMarkov(seqs)
+	}
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	// Cada permutación usa su propio generador (semilla + k), así el
+	// resultado no depende de la cantidad de workers ni del orden
+	var (
+		mu      sync.Mutex
+		sum     = make([]int, len(patterns)) // soporte nulo acumulado
+		extreme = make([]int, len(patterns)) // nulos con soporte >= observado
+		next    = make(chan int)
+		wg      sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			null := make([]string, len(seqs))
+			local := make([]int, len(patterns))
+			for k := range next {
+				rng := rand.New(rand.NewSource(opts.Seed + int64(k)))
+				for i, s := range seqs {
+					if markov != nil {
+						null[i] = markov.Generate(len(s), rng)
+					} else {
+						null[i] = Shuffle(s, rng)
+					}
+				}
+				for i, m := range matchers {
+					local[i] = m.Support(null)
+				}
+				mu.Lock()
+				for i, n := range local {
+					sum[i] += n
+					if n >= results[i].Observed {
+						extreme[i]++
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	var err error
+	for k := 0; k < opts.Permutations; k++ {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		next <- k
+	}
+	close(next)
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	p := make([]float64, len(results))
+	for i := range results {
+		results[i].Expected = float64(sum[i]) / float64(opts.Permutations)
+		results[i].PValue = float64(1+extreme[i]) / float64(1+opts.Permutations)
+		p[i] = results[i].PValue
+	}
+	for i, q := range BenjaminiHochberg(p) {
+		results[i].QValue = q
+	}
+	return results, nil
+}
+
+// Stats analiza los patrones de un mapa de estadísticas (como el de
+// gaps.ConsolidatePatterns) y devuelve los resultados por patrón.
+func Stats(ctx context.Context, seqs []string, stats map[string]*gaps.PatternStat, opts Options) (map[string]Result, error) {
+	patterns := make([]string, 0, len(stats))
+	for p := range stats {
+		patterns = append(patterns, p)
+	}
+	sort.Strings(patterns)
+	results, err := Analyze(ctx, seqs, patterns, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]Result, len(results))
+	for _, r := range results {
+		out[r.Pattern] = r
+	}
+	return out, nil
+}
+
+// Shuffle devuelve una permutación al azar de los residuos de s (conserva
+// la composición, incluidas mayúsculas y minúsculas).
+func Shuffle(s string, rng *rand.Rand) string {
+	b := []byte(s)
+	rng.Shuffle(len(b), func(i, j int) { b[i], b[j] = b[j], b[i] })
+	return string(b)
+}
+
+// BenjaminiHochberg ajusta p-valores por comparaciones múltiples (FDR):
+// q_i = min_{j: p_j >= p_i} p_j·m/rango(j), acotado a 1. Devuelve los
+// q-valores en el orden de p.
+func BenjaminiHochberg(p []float64) []float64 {
+	m := len(p)
+	order := make([]int, m)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return p[order[a]] < p[order[b]] })
+
+	q := make([]float64, m)
+	running := 1.0
+	for r := m - 1; r >= 0; r-- {
+		i := order[r]
+		if v := p[i] * float64(m) / float64(r+1); v < running {
+			running = v
+		}
+		q[i] = running
+	}
+	return q
+}
@@ -0,0 +1,86 @@
+package enrich
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Markov es una cadena de Markov de orden 1 sobre residuos (distingue
+// mayúsculas y minúsculas), el modelo de fondo de NullMarkov.
+type Markov struct {
+	start dist      // primer residuo de cada secuencia
+	next  [256]dist // residuo siguiente según el anterior
+	all   dist      // frecuencia global, para residuos sin sucesores
+}
+
+// dist es una distribución discreta sobre residuos.
+type dist struct {
+	symbols []byte
+	cum     []int // frecuencias acumuladas
+}
+
+func newDist(counts map[byte]int) dist {
+	var d dist
+	for c := range counts {
+		d.symbols = append(d.symbols, c)
+	}
+	sort.Slice(d.symbols, func(i, j int) bool { return d.symbols[i] < d.symbols[j] })
+	total := 0
+	for _, c := range d.symbols {
+		total += counts[c]
+		d.cum = append(d.cum, total)
+	}
+	return d
+}
+
+func (d dist) empty() bool { return len(d.symbols) == 0 }
+
+func (d dist) sample(rng *rand.Rand) byte {
+	x := rng.Intn(d.cum[len(d.cum)-1])
+	return d.symbols[sort.SearchInts(d.cum, x+1)]
+}
+
+// TrainMarkov estima las transiciones con las secuencias dadas.
+func TrainMarkov(seqs []string) *Markov {
+	start := make(map[byte]int)
+	all := make(map[byte]int)
+	var pairs [256]map[byte]int
+	for _, s := range seqs {
+		for i := 0; i < len(s); i++ {
+			all[s[i]]++
+			if i == 0 {
+				start[s[i]]++
+				continue
+			}
+			prev := s[i-1]
+			if pairs[prev] == nil {
+				pairs[prev] = make(map[byte]int)
+			}
+			pairs[prev][s[i]]++
+		}
+	}
+	m := &Markov{start: newDist(start), all: newDist(all)}
+	for c, counts := range pairs {
+		if counts != nil {
+			m.next[c] = newDist(counts)
+		}
+	}
+	return m
+}
+
+// Generate genera una secuencia de n residuos.
+func (m *Markov) Generate(n int, rng *rand.Rand) string {
+	if n == 0 || m.all.empty() {
+		return ""
+	}
+	b := make([]byte, n)
+	b[0] = m.start.sample(rng)
+	for i := 1; i < n; i++ {
+		d := m.next[b[i-1]]
+		if d.empty() {
+			d = m.all
+		}
+		b[i] = d.sample(rng)
+	}
+	return string(b)
+}
@@ -134,6 +134,12 @@ func MinGapsDistanceTotalViable(seq string, pattern string) ([]int, bool) {
 // Retorna un slice de sets (map[int]struct{}) de longitud len(pattern)-1.
 // Optimizado para usar menos memoria: procesa gaps incrementalmente sin almacenar rutas completas.
 func AllGapValuesDistanceTotalViable(seq string, pattern string) ([]map[int]struct{}, bool) {
+	return viableGapValues(seq, pattern, func(a, b int) int { return b - a - 1 })
+}
+
+// viableGapValues es AllGapValuesDistanceTotalViable midiendo cada gap entre
+// las posiciones a < b de seq con dist (ver GapValues).
+func viableGapValues(seq string, pattern string, dist func(a, b int) int) ([]map[int]struct{}, bool) {
 	L := len(pattern)
 	if L <= 1 {
 		return make([]map[int]struct{}, 0), true
@@ -222,7 +228,7 @@ func AllGapValuesDistanceTotalViable(seq string, pattern string) ([]map[int]stru
 			
 			// Si no es el primer carácter, registrar el gap inmediatamente
 			if k > 0 {
-				gap := currentPos - prevPos - 1
+				gap := dist(prevPos, currentPos)
 				sets[k-1][gap] = struct{}{}
 			}
 			
@@ -251,110 +257,77 @@ type PatternStat struct {
 
 // patternGroup agrupa patrones con la misma base
 type patternGroup struct {
-	letters    string
+	letters    []string // letras o clases ([CH]) del patrón
 	gapCount   int
 	gapValues  [][]int      // gapValues[i] = valores del gap i
 	seqIndices map[int]bool
 	patterns   []string // patrones originales
 }
 
-// parsePattern extrae la base del patrón (letras mayúsculas) y los valores de gaps
+// parsePattern extrae la base del patrón (residuos, clases o exclusiones,
+// en la sintaxis de ParsePattern) y los valores de gaps
 // Devuelve un slice de slices donde gapsAfter[i] contiene los valores del gap DESPUÉS de la letra i
-// Maneja tanto x(n) como x(min,max) expandiendo los rangos
-// Ejemplo: "C-x(2,4)-H" -> letters="CH", gaps=[[2,3,4]]
-func parsePattern(pattern string) (string, [][]int) {
-	var letters strings.Builder
+// Los x(min,max) entre dos posiciones se expanden (x(0) = posiciones contiguas)
+// y los x seguidos se suman; un residuo repetido C(2) son dos posiciones
+// contiguas. Las anclas '<' y '>' quedan pegadas a la primera y la última letra.
+// Ejemplo: "C-x(2,4)-H" -> letters=["C","H"], gaps=[[2,3,4]]
+// Ejemplo: "[CH]-x(2)-C" -> letters=["[CH]","C"], gaps=[[2]]
+func parsePattern(pattern string) ([]string, [][]int, error) {
+	pat, err := ParsePattern(pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	letters := []string{}
 	gapsAfter := [][]int{} // gaps después de cada letra (vacío si no hay)
-
-	i := 0
-	for i < len(pattern) {
-		r := rune(pattern[i])
-
-		// Si es una letra mayúscula, agregarla
-		if r >= 'A' && r <= 'Z' {
-			letters.WriteRune(r)
-			// Por defecto, no hay gap después de esta letra
-			gapsAfter = append(gapsAfter, []int{})
-			i++
+	lo, hi := 0, 0         // gap acumulado desde la última letra
+	for k, el := range pat.Elements {
+		// Los x entre dos posiciones son gaps; al comienzo o al final del
+		// patrón se conservan como un símbolo más
+		if el.Kind == Any && len(letters) > 0 && k < len(pat.Elements)-1 {
+			lo += el.Min
+			hi += el.Max
 			continue
 		}
-
-		// Si encontramos x(, extraer el número o rango
-		if i+2 < len(pattern) && pattern[i] == 'x' && pattern[i+1] == '(' {
-			i += 2 // saltar "x("
-			
-			// Extraer primer número
-			numStart := i
-			for i < len(pattern) && pattern[i] >= '0' && pattern[i] <= '9' {
-				i++
+		if len(letters) > 0 {
+			gapValues := []int{}
+			for v := max(lo, 1); v <= hi; v++ { // Ignorar x(0)
+				gapValues = append(gapValues, v)
 			}
-			
-			var gapValues []int
-			if numStart < i && len(gapsAfter) > 0 {
-				num1 := 0
-				for j := numStart; j < i; j++ {
-					num1 = num1*10 + int(pattern[j]-'0')
-				}
-				
-				// Verificar si hay un rango (coma o guión)
-				if i < len(pattern) && (pattern[i] == ',' || pattern[i] == '-') {
-					i++ // saltar ',' o '-'
-					numStart = i
-					for i < len(pattern) && pattern[i] >= '0' && pattern[i] <= '9' {
-						i++
-					}
-					if numStart < i {
-						num2 := 0
-						for j := numStart; j < i; j++ {
-							num2 = num2*10 + int(pattern[j]-'0')
-						}
-						// Expandir rango [num1, num2]
-						for v := num1; v <= num2; v++ {
-							if v > 0 { // Ignorar x(0)
-								gapValues = append(gapValues, v)
-							}
-						}
-					}
-				} else if num1 > 0 { // Valor único, ignorar x(0)
-					gapValues = []int{num1}
-				}
-				
-				// Asignar los valores al gap de la última letra
-				if len(gapValues) > 0 {
-					gapsAfter[len(gapsAfter)-1] = gapValues
+			gapsAfter = append(gapsAfter, gapValues)
+			lo, hi = 0, 0
+		}
+
+		if el.Kind != Any && el.Min == el.Max {
+			base := el
+			base.Min, base.Max = 1, 1
+			for r := 0; r < el.Min; r++ {
+				if r > 0 {
+					gapsAfter = append(gapsAfter, []int{})
 				}
-			}
-			
-			// Saltar hasta después del ')'
-			for i < len(pattern) && pattern[i] != ')' {
-				i++
-			}
-			if i < len(pattern) {
-				i++ // saltar ')'
+				letters = append(letters, base.String())
 			}
 			continue
 		}
-
-		i++
+		letters = append(letters, el.String())
 	}
 
-	// Quitar el último elemento de gapsAfter (no hay gap después de la última letra)
-	if len(gapsAfter) > 0 {
-		gapsAfter = gapsAfter[:len(gapsAfter)-1]
+	if pat.NTerm {
+		letters[0] = "<" + letters[0]
 	}
-
-	return letters.String(), gapsAfter
+	if pat.CTerm {
+		letters[len(letters)-1] += ">"
+	}
+	return letters, gapsAfter, nil
 }
 
-// countUppercaseInPattern cuenta las letras mayúsculas en una cadena
+// countUppercaseInPattern cuenta las posiciones (letras o clases) de un patrón
 func countUppercaseInPattern(s string) int {
-	count := 0
-	for _, r := range s {
-		if r >= 'A' && r <= 'Z' {
-			count++
-		}
+	pat, err := ParsePattern(s)
+	if err != nil {
+		return 0
 	}
-	return count
+	return pat.Positions()
 }
 
 // sortInts ordena un slice de enteros in-place
@@ -368,19 +341,6 @@ func sortInts(arr []int) {
 	}
 }
 
-// formatGapRange formatea un rango de gaps consecutivos
-// Asume que los gaps ya son consecutivos
-func formatGapRange(gaps []int) string {
-	if len(gaps) == 0 {
-		return ""
-	}
-	if len(gaps) == 1 {
-		return "(" + itoa(gaps[0]) + ")"
-	}
-	// Formato (min,max) para rangos consecutivos
-	return "(" + itoa(gaps[0]) + "," + itoa(gaps[len(gaps)-1]) + ")"
-}
-
 // findConsecutiveRanges divide una lista de enteros en rangos consecutivos
 // Ejemplo: [1, 3, 11, 13, 15, 16, 18] -> [[1], [3], [11], [13], [15,16], [18]]
 func findConsecutiveRanges(gaps []int) [][]int {
@@ -433,26 +393,25 @@ func itoa(n int) string {
 // buildConsolidatedPattern construye el patrón final con gaps consolidados
 // gapValues[i] contiene los valores del gap DESPUÉS de la letra i
 // Si gapValues[i] está vacío, no hay gap después de esa letra
-func buildConsolidatedPattern(letters string, gapValues [][]int) string {
+func buildConsolidatedPattern(letters []string, gapValues [][]int) string {
 	if len(letters) == 0 {
 		return ""
 	}
 
 	var result strings.Builder
-	letterRunes := []rune(letters)
 
-	for i, letter := range letterRunes {
+	for i, letter := range letters {
 		if i > 0 {
 			result.WriteString("-")
 		}
-		result.WriteRune(letter)
+		result.WriteString(letter)
 
-		// Agregar gap si existe y no es la última letra
+		// Agregar gap si existe y no es la última letra (los valores son
+		// consecutivos, así x(min,max) es exacto)
 		if i < len(gapValues) {
-			gaps := gapValues[i]
-			if len(gaps) > 0 {
-				result.WriteString("-x")
-				result.WriteString(formatGapRange(gaps))
+			if gap, ok := GapElement(gapValues[i]); ok {
+				result.WriteString("-")
+				result.WriteString(gap.String())
 			}
 		}
 	}
@@ -466,8 +425,14 @@ func buildConsolidatedPattern(letters string, gapValues [][]int) string {
 func ConsolidatePatterns(stats map[string]*PatternStat) map[string]*PatternStat {
 	groups := make(map[string]*patternGroup)
 
+	passthrough := make(map[string]*PatternStat)
 	for pattern, stat := range stats {
-		letters, gaps := parsePattern(pattern)
+		letters, gaps, err := parsePattern(pattern)
+		if err != nil {
+			// Patrón que no es PROSITE válido: se conserva tal cual
+			passthrough[pattern] = stat
+			continue
+		}
 		
 		// La clave incluye las letras y las posiciones de los gaps (cuáles tienen gap y cuáles no)
 		// Esto asegura que solo se agrupen patrones con la misma estructura
@@ -477,7 +442,7 @@ func ConsolidatePatterns(stats map[string]*PatternStat) map[string]*PatternStat
 				gapPositions += itoa(i) + ","
 			}
 		}
-		key := letters + "|" + gapPositions
+		key := strings.Join(letters, "") + "|" + gapPositions
 
 		if _, exists := groups[key]; !exists {
 			gapValues := make([][]int, len(gaps))
@@ -524,6 +489,9 @@ func ConsolidatePatterns(stats map[string]*PatternStat) map[string]*PatternStat
 
 	// Construir patrones consolidados
 	result := make(map[string]*PatternStat)
+	for pattern, stat := range passthrough {
+		result[pattern] = stat
+	}
 
 	for _, g := range groups {
 		// Ordenar cada lista de gaps
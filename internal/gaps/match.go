@@ -0,0 +1,103 @@
+package gaps
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lucckkas/patternfinder/internal/utils"
+)
+
+// ---------------- SEMÁNTICA DE COINCIDENCIA ----------------
+
+// Semánticas de coincidencia entre un patrón y una secuencia. En las
+// secuencias de Interactions.py las mayúsculas son los residuos que
+// interactúan con el ligando y las minúsculas el resto.
+const (
+	// MatchDefault: los residuos del patrón coinciden con mayúsculas y un
+	// gap es la distancia total entre dos posiciones (cuenta residuos de
+	// cualquier caso). Es la semántica histórica de
+	// AllGapValuesDistanceTotalViable.
+	MatchDefault = "default"
+	// MatchStrict: como MatchDefault, pero los residuos dentro de los gaps
+	// deben ser minúsculas (no puede haber otro residuo que interactúe entre
+	// dos posiciones del patrón).
+	MatchStrict = "strict"
+	// MatchRelaxed: los residuos del patrón coinciden en mayúsculas o
+	// minúsculas.
+	MatchRelaxed = "relaxed"
+	// MatchInteraction: los gaps cuentan solo residuos en mayúsculas; las
+	// minúsculas no ocupan lugar.
+	MatchInteraction = "interaction"
+)
+
+// CheckMatch valida el nombre de una semántica ("" equivale a MatchDefault).
+func CheckMatch(mode string) error {
+	switch mode {
+	case "", MatchDefault, MatchStrict, MatchRelaxed, MatchInteraction:
+		return nil
+	}
+	return fmt.Errorf("semántica de coincidencia desconocida %q (use default, strict, relaxed o interaction)", mode)
+}
+
+// MatchSequence prepara una secuencia para buscar patrones con mode: con
+// MatchRelaxed la pasa a mayúsculas, con el resto la devuelve igual. Sirve
+// para traducir clases después (las clases solo cambian mayúsculas).
+func MatchSequence(seq, mode string) string {
+	if mode == MatchRelaxed {
+		return strings.ToUpper(seq)
+	}
+	return seq
+}
+
+// AllGapValues es AllGapValuesDistanceTotalViable con la semántica mode:
+// para cada par consecutivo del patrón (letras en mayúsculas) devuelve todos
+// los valores de gap posibles en seq.
+func AllGapValues(seq, pattern, mode string) ([]map[int]struct{}, bool) {
+	return GapValues(utils.Project(MatchSequence(seq, mode)), pattern, mode)
+}
+
+// GapValues es AllGapValues sobre la proyección de MatchSequence(seq, mode)
+// (que puede estar traducida a clases): las incrustaciones del patrón se
+// buscan en p.Upper y cada gap se mide con p.Distance, o en mayúsculas con
+// MatchInteraction.
+func GapValues(p *utils.Projection, pattern, mode string) ([]map[int]struct{}, bool) {
+	switch mode {
+	case MatchInteraction:
+		// Las minúsculas no ocupan lugar: la distancia es entre índices de Upper
+		return viableGapValues(p.Upper, pattern, func(k1, k2 int) int { return k2 - k1 - 1 })
+	case MatchStrict:
+		return strictGapValues(p, pattern)
+	}
+	return viableGapValues(p.Upper, pattern, p.Distance)
+}
+
+// strictGapValues: sin mayúsculas dentro de los gaps, el patrón tiene que
+// aparecer como bloque contiguo en la proyección a mayúsculas; cada aparición
+// da un valor por gap (la distancia total en la secuencia original).
+func strictGapValues(p *utils.Projection, pattern string) ([]map[int]struct{}, bool) {
+	L := len(pattern)
+	if L <= 1 {
+		return make([]map[int]struct{}, 0), true
+	}
+	sets := make([]map[int]struct{}, L-1)
+	for i := range sets {
+		sets[i] = make(map[int]struct{})
+	}
+	found := false
+	for from := 0; ; {
+		k := strings.Index(p.Upper[from:], pattern)
+		if k < 0 {
+			break
+		}
+		start := from + k
+		for i := 0; i+1 < L; i++ {
+			sets[i][p.Distance(start+i, start+i+1)] = struct{}{}
+		}
+		found = true
+		from = start + 1 // las apariciones pueden solaparse
+	}
+	if !found {
+		return nil, false
+	}
+	return sets, true
+}
@@ -0,0 +1,342 @@
+package gaps
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ---------------- SINTAXIS PROSITE ----------------
+
+// Kind es el tipo de un elemento de patrón.
+type Kind int
+
+const (
+	Residue Kind = iota // una letra (C) o una clase de letras aceptadas ([CH])
+	Exclude             // letras excluidas ({P})
+	Any                 // cualquier residuo (x)
+)
+
+// Element es un elemento de un patrón PROSITE con su repetición:
+// C, [CH], {P} o x, opcionalmente seguido de (n) o (n,m).
+type Element struct {
+	Kind     Kind
+	Residues string // letras aceptadas (Residue) o excluidas (Exclude), en el orden escrito
+	CTerm    bool   // la clase acepta también el final de la secuencia: [G>]
+	Min, Max int    // repetición; 1,1 si no se indica
+	Pos      int    // posición del elemento en el texto (base 0)
+}
+
+// Pattern es un patrón PROSITE: elementos separados por '-', con anclas
+// opcionales '<' (comienzo de la secuencia) y '>' (final).
+type Pattern struct {
+	NTerm    bool
+	CTerm    bool
+	Elements []Element
+}
+
+// SyntaxError es un error de sintaxis en un patrón, con su posición.
+type SyntaxError struct {
+	Pattern string
+	Pos     int // base 0
+	Msg     string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("patrón %q, columna %d: %s", e.Pattern, e.Pos+1, e.Msg)
+}
+
+// maxRepeat acota los números de las repeticiones.
+const maxRepeat = 1 << 20
+
+// ParsePattern lee un patrón con la sintaxis de PROSITE:
+//
+//	C          un residuo
+//	[CH]       cualquiera de los residuos (con '>' al final, también el final de la secuencia: [G>])
+//	{P}        cualquier residuo salvo los indicados
+//	x          cualquier residuo
+//	e(n)       el elemento e repetido n veces, ej. x(2) o C(2)
+//	e(n,m)     entre n y m repeticiones, ej. x(2,4)
+//	<  >       al comienzo / al final del patrón: anclado al extremo N / C
+//
+// Los elementos se separan con '-' y el patrón puede terminar en '.'. Se
+// ignoran los espacios. Además de PROSITE se acepta x(0) (residuos
+// contiguos), que aparecía en patrones generados por versiones anteriores.
+func ParsePattern(s string) (Pattern, error) {
+	p := &parser{src: s}
+	pat, err := p.pattern()
+	if err != nil {
+		return Pattern{}, err
+	}
+	return pat, nil
+}
+
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) errorf(pos int, format string, args ...any) error {
+	return &SyntaxError{Pattern: p.src, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// peek devuelve el próximo byte que no es espacio (0 al final).
+func (p *parser) peek() byte {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func describe(c byte) string {
+	if c == 0 {
+		return "el final del patrón"
+	}
+	return fmt.Sprintf("%q", c)
+}
+
+func (p *parser) pattern() (Pattern, error) {
+	var pat Pattern
+	if p.peek() == 0 {
+		return pat, p.errorf(0, "patrón vacío")
+	}
+	if p.peek() == '<' {
+		pat.NTerm = true
+		p.pos++
+	}
+	for {
+		el, err := p.element()
+		if err != nil {
+			return pat, err
+		}
+		pat.Elements = append(pat.Elements, el)
+
+		switch c := p.peek(); c {
+		case '-':
+			p.pos++
+			continue
+		case '>':
+			pat.CTerm = true
+			p.pos++
+			if c := p.peek(); c != '.' && c != 0 {
+				return pat, p.errorf(p.pos-1, "'>' solo puede ir al final del patrón")
+			}
+		case '.', 0:
+		case '<':
+			return pat, p.errorf(p.pos, "'<' solo puede ir al comienzo del patrón")
+		default:
+			return pat, p.errorf(p.pos, "se esperaba '-' entre elementos, se encontró %s", describe(c))
+		}
+		break
+	}
+	if p.peek() == '.' {
+		p.pos++
+	}
+	if c := p.peek(); c != 0 {
+		return pat, p.errorf(p.pos, "texto de más después del final del patrón: %s", describe(c))
+	}
+	return pat, nil
+}
+
+func (p *parser) element() (Element, error) {
+	el := Element{Min: 1, Max: 1}
+	c := p.peek()
+	el.Pos = p.pos
+	switch {
+	case c == 'x':
+		el.Kind = Any
+		p.pos++
+	case c >= 'A' && c <= 'Z':
+		el.Kind = Residue
+		el.Residues = string(c)
+		p.pos++
+	case c == '[':
+		el.Kind = Residue
+		if err := p.residues(&el, ']'); err != nil {
+			return el, err
+		}
+	case c == '{':
+		el.Kind = Exclude
+		if err := p.residues(&el, '}'); err != nil {
+			return el, err
+		}
+	case c >= 'a' && c <= 'z':
+		return el, p.errorf(p.pos, "residuo %q en minúscula (los residuos van en mayúsculas; 'x' es cualquier residuo)", c)
+	case c == '-' || c == '>' || c == '.' || c == 0:
+		return el, p.errorf(p.pos, "se esperaba un elemento, se encontró %s", describe(c))
+	default:
+		return el, p.errorf(p.pos, "carácter inesperado %s", describe(c))
+	}
+
+	if p.peek() == '(' {
+		if err := p.repeat(&el); err != nil {
+			return el, err
+		}
+	}
+	return el, nil
+}
+
+// residues lee el contenido de una clase [..] o exclusión {..}.
+func (p *parser) residues(el *Element, closing byte) error {
+	open := p.pos
+	p.pos++ // '[' o '{'
+	var b strings.Builder
+	for {
+		c := p.peek()
+		switch {
+		case c == closing:
+			p.pos++
+			if b.Len() == 0 && !el.CTerm {
+				return p.errorf(open, "%c%c vacío", p.src[open], closing)
+			}
+			el.Residues = b.String()
+			return nil
+		case c == 0:
+			return p.errorf(open, "falta %q para cerrar %q", closing, p.src[open])
+		case c == '>' && closing == ']':
+			// [G>]: solo al final de la clase
+			el.CTerm = true
+			p.pos++
+			if p.peek() != ']' {
+				return p.errorf(p.pos-1, "'>' solo puede ir al final de una clase")
+			}
+		case c >= 'A' && c <= 'Z':
+			if strings.IndexByte(b.String(), c) >= 0 {
+				return p.errorf(p.pos, "residuo %q repetido", c)
+			}
+			b.WriteByte(c)
+			p.pos++
+		default:
+			return p.errorf(p.pos, "carácter inesperado %s dentro de %c%c", describe(c), p.src[open], closing)
+		}
+	}
+}
+
+// repeat lee (n) o (n,m) después de un elemento.
+func (p *parser) repeat(el *Element) error {
+	open := p.pos
+	p.pos++ // '('
+	n, err := p.number()
+	if err != nil {
+		return err
+	}
+	m := n
+	if p.peek() == ',' {
+		p.pos++
+		if m, err = p.number(); err != nil {
+			return err
+		}
+	}
+	if c := p.peek(); c != ')' {
+		if c == 0 {
+			return p.errorf(open, "falta ')' para cerrar '('")
+		}
+		return p.errorf(p.pos, "se esperaba ',' o ')', se encontró %s", describe(c))
+	}
+	p.pos++
+	switch {
+	case n > m:
+		return p.errorf(open, "repetición (%d,%d) con mínimo mayor que el máximo", n, m)
+	case n == 0 && el.Kind != Any:
+		// x(0) se acepta por compatibilidad: residuos contiguos
+		return p.errorf(open, "solo x admite 0 repeticiones")
+	}
+	el.Min, el.Max = n, m
+	return nil
+}
+
+func (p *parser) number() (int, error) {
+	p.peek()
+	start := p.pos
+	for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+	if start == p.pos {
+		return 0, p.errorf(start, "se esperaba un número, se encontró %s", describe(p.peek()))
+	}
+	n, err := strconv.Atoi(p.src[start:p.pos])
+	if err != nil || n > maxRepeat {
+		return 0, p.errorf(start, "número demasiado grande %s", p.src[start:p.pos])
+	}
+	return n, nil
+}
+
+// String imprime el elemento con la sintaxis de PROSITE. x se imprime
+// siempre con su longitud (x(1)), como en los patrones que genera el programa.
+func (e Element) String() string {
+	var b strings.Builder
+	switch e.Kind {
+	case Any:
+		b.WriteByte('x')
+	case Exclude:
+		b.WriteString("{" + e.Residues + "}")
+	default:
+		if len(e.Residues) == 1 && !e.CTerm {
+			b.WriteString(e.Residues)
+		} else {
+			b.WriteString("[" + e.Residues)
+			if e.CTerm {
+				b.WriteByte('>')
+			}
+			b.WriteByte(']')
+		}
+	}
+	switch {
+	case e.Min == 1 && e.Max == 1 && e.Kind != Any:
+	case e.Min == e.Max:
+		fmt.Fprintf(&b, "(%d)", e.Min)
+	default:
+		fmt.Fprintf(&b, "(%d,%d)", e.Min, e.Max)
+	}
+	return b.String()
+}
+
+// String imprime el patrón con la sintaxis de PROSITE (sin '.' final).
+// ParsePattern(p.String()) devuelve un patrón equivalente.
+func (p Pattern) String() string {
+	parts := make([]string, len(p.Elements))
+	for i, e := range p.Elements {
+		parts[i] = e.String()
+	}
+	s := strings.Join(parts, "-")
+	if p.NTerm {
+		s = "<" + s
+	}
+	if p.CTerm {
+		s += ">"
+	}
+	return s
+}
+
+// Positions cuenta las posiciones fijas del patrón: residuos, clases y
+// exclusiones (con su repetición mínima), sin contar los x.
+func (p Pattern) Positions() int {
+	n := 0
+	for _, e := range p.Elements {
+		if e.Kind != Any {
+			n += e.Min
+		}
+	}
+	return n
+}
+
+// GapRuns divide los valores (ordenados) de un gap en rangos contiguos.
+// PROSITE no tiene alternativas de longitud, así que cada rango es un patrón
+// distinto: {2,3,5} da [[2 3] [5]], o sea x(2,3) y x(5); x(2,5) diría que
+// también se observó 4.
+func GapRuns(vals []int) [][]int {
+	return findConsecutiveRanges(vals)
+}
+
+// GapElement devuelve el elemento x que representa un rango contiguo de
+// valores de gap (ver GapRuns): x(k) o x(min,max). ok es false si no hay gap
+// (vals vacío o solo 0: residuos contiguos).
+func GapElement(vals []int) (Element, bool) {
+	if len(vals) == 0 || vals[len(vals)-1] == 0 {
+		return Element{}, false
+	}
+	return Element{Kind: Any, Min: vals[0], Max: vals[len(vals)-1]}, true
+}
@@ -0,0 +1,58 @@
+package lcs
+
+// Match es una posición conservada de una LCS: índices (base 0) en las
+// cadenas de mayúsculas comparadas. Las posiciones en las secuencias
+// originales se obtienen con utils.Projection.OriginalPositions.
+type Match struct {
+	I, J int
+}
+
+// Alignment es una LCS junto con una incrustación en ambas secuencias:
+// Matches[k] indica dónde aparece LCS[k] en cada una.
+type Alignment struct {
+	LCS     string
+	Matches []Match
+}
+
+// IndicesX devuelve los índices (base 0) en las mayúsculas de la secuencia 1.
+func (a Alignment) IndicesX() []int {
+	out := make([]int, len(a.Matches))
+	for k, m := range a.Matches {
+		out[k] = m.I
+	}
+	return out
+}
+
+// IndicesY devuelve los índices (base 0) en las mayúsculas de la secuencia 2.
+func (a Alignment) IndicesY() []int {
+	out := make([]int, len(a.Matches))
+	for k, m := range a.Matches {
+		out[k] = m.J
+	}
+	return out
+}
+
+// EmbedLCS construye la incrustación más a la izquierda de una LCS ya
+// conocida (de Backtracking, Enumerator o muestreo) en sec1 y sec2. Los
+// valores de gap no salen de aquí: gaps.GapValues recorre todas las
+// incrustaciones viables de cada secuencia por separado.
+// Devuelve false si s no es subsecuencia común de ambas.
+func EmbedLCS(s, sec1, sec2 string) (Alignment, bool) {
+	matches := make([]Match, 0, len(s))
+	i, j := 0, 0
+	for k := 0; k < len(s); k++ {
+		for i < len(sec1) && sec1[i] != s[k] {
+			i++
+		}
+		for j < len(sec2) && sec2[j] != s[k] {
+			j++
+		}
+		if i == len(sec1) || j == len(sec2) {
+			return Alignment{}, false
+		}
+		matches = append(matches, Match{I: i, J: j})
+		i++
+		j++
+	}
+	return Alignment{LCS: s, Matches: matches}, true
+}
@@ -0,0 +1,63 @@
+package lcs
+
+import "math/bits"
+
+// matchMasks precalcula, para cada letra de sec1, la máscara de bits con las
+// posiciones donde aparece (bit i de la palabra i/64). Las letras ausentes
+// quedan en nil.
+func matchMasks(sec1 string, words int) *[256][]uint64 {
+	var masks [256][]uint64
+	for i := 0; i < len(sec1); i++ {
+		c := sec1[i]
+		if masks[c] == nil {
+			masks[c] = make([]uint64, words)
+		}
+		masks[c][i/64] |= 1 << uint(i%64)
+	}
+	return &masks
+}
+
+// LengthBitParallel calcula la longitud de la LCS con el algoritmo
+// bit-paralelo de Allison–Dix / Hyyrö: cada columna de la tabla DP se codifica
+// en palabras de 64 bits y se actualiza con sumas y operaciones lógicas, en
+// O(ceil(n/64)·m). El resultado es idéntico a DPTable(sec1, sec2)[n][m].
+func LengthBitParallel(sec1, sec2 string) int {
+	n := len(sec1)
+	if n == 0 || len(sec2) == 0 {
+		return 0
+	}
+	words := (n + 63) / 64
+	masks := matchMasks(sec1, words)
+
+	// V tiene un 1 en cada fila donde la columna NO incrementa la LCS
+	V := make([]uint64, words)
+	for w := range V {
+		V[w] = ^uint64(0)
+	}
+
+	for j := 0; j < len(sec2); j++ {
+		M := masks[sec2[j]]
+		if M == nil {
+			continue // sin coincidencias: la columna no cambia
+		}
+		// V = (V + (V & M)) | (V & ^M), con acarreo entre palabras
+		var carry uint64
+		for w := 0; w < words; w++ {
+			u := V[w] & M[w]
+			sum, c := bits.Add64(V[w], u, carry)
+			carry = c
+			V[w] = sum | (V[w] &^ u)
+		}
+	}
+
+	// La LCS es el número de ceros en las n filas válidas
+	zeros := 0
+	for w := 0; w < words; w++ {
+		valid := ^uint64(0)
+		if rem := n - w*64; rem < 64 {
+			valid = (uint64(1) << uint(rem)) - 1
+		}
+		zeros += bits.OnesCount64(^V[w] & valid)
+	}
+	return zeros
+}
@@ -0,0 +1,131 @@
+package lcs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ClassPresets son conjuntos de clases de equivalencia predefinidos para -classes.
+var ClassPresets = map[string]string{
+	"metal":    "[CH][DE]",                        // ligandos típicos de metales
+	"charge":   "[DE][KR]",                        // ácidos / básicos
+	"basic":    "[CH][DE][KR][ILVM]",              // metal + carga + hidrofóbicos alifáticos
+	"chemical": "[CH][DE][KR][ILVM][FWY][ST][NQ]", // grupos químicos habituales
+}
+
+// Classes agrupa residuos intercambiables (ej. C/H o D/E). Para comparar por
+// clase, cada mayúscula se traduce al representante de su grupo (la primera
+// letra del grupo) y se corre el LCS habitual sobre las secuencias traducidas;
+// al imprimir, cada representante se muestra como el grupo entre corchetes
+// al estilo PROSITE, ej. [CH]-x(2)-C.
+type Classes struct {
+	groups []string
+	rep    [256]byte // letra -> representante (0 si no pertenece a un grupo)
+	group  [256]int  // representante -> índice en groups + 1
+}
+
+// ParseClasses interpreta una especificación de clases: el nombre de un
+// preset (ver ClassPresets) o grupos entre corchetes como "[CH][DE][KR]".
+// Una especificación vacía devuelve nil (sin clases).
+func ParseClasses(spec string) (*Classes, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	if preset, ok := ClassPresets[strings.ToLower(spec)]; ok {
+		spec = preset
+	}
+
+	c := &Classes{}
+	i := 0
+	for i < len(spec) {
+		if spec[i] != '[' {
+			return nil, fmt.Errorf("clases: se esperaba '[' en la posición %d de %q", i+1, spec)
+		}
+		end := strings.IndexByte(spec[i:], ']')
+		if end < 0 {
+			return nil, fmt.Errorf("clases: falta ']' para el grupo que empieza en la posición %d", i+1)
+		}
+		members := spec[i+1 : i+end]
+		if len(members) == 0 {
+			return nil, fmt.Errorf("clases: grupo vacío en la posición %d", i+1)
+		}
+		rep := members[0]
+		for k := 0; k < len(members); k++ {
+			b := members[k]
+			if b < 'A' || b > 'Z' {
+				return nil, fmt.Errorf("clases: %q no es una letra mayúscula (posición %d)", b, i+2+k)
+			}
+			if c.rep[b] != 0 {
+				return nil, fmt.Errorf("clases: la letra %c aparece en más de un grupo", b)
+			}
+			c.rep[b] = rep
+		}
+		c.groups = append(c.groups, members)
+		c.group[rep] = len(c.groups)
+		i += end + 1
+	}
+	return c, nil
+}
+
+// PresetNames devuelve los nombres de los presets ordenados.
+func PresetNames() []string {
+	names := make([]string, 0, len(ClassPresets))
+	for name := range ClassPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Translate reemplaza cada mayúscula por el representante de su clase. Las
+// minúsculas no cambian, así las posiciones y distancias se conservan.
+func (c *Classes) Translate(s string) string {
+	if c == nil {
+		return s
+	}
+	out := []byte(s)
+	for i, b := range out {
+		if r := c.rep[b]; r != 0 {
+			out[i] = r
+		}
+	}
+	return string(out)
+}
+
+// Symbol devuelve cómo se imprime un símbolo de patrón: el grupo entre
+// corchetes si es representante de una clase, o la letra misma.
+func (c *Classes) Symbol(b byte) string {
+	if c != nil {
+		if g := c.group[b]; g > 0 && len(c.groups[g-1]) > 1 {
+			return "[" + c.groups[g-1] + "]"
+		}
+	}
+	return string(b)
+}
+
+// Render traduce un patrón de representantes a su forma con clases, ej.
+// "CHD" -> "[CH][CH][DE]".
+func (c *Classes) Render(pattern string) string {
+	if c == nil {
+		return pattern
+	}
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		b.WriteString(c.Symbol(pattern[i]))
+	}
+	return b.String()
+}
+
+// String devuelve la especificación canónica, ej. "[CH][DE]".
+func (c *Classes) String() string {
+	if c == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, g := range c.groups {
+		b.WriteString("[" + g + "]")
+	}
+	return b.String()
+}
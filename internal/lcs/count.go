@@ -0,0 +1,145 @@
+package lcs
+
+import (
+	"math/big"
+	"math/rand"
+)
+
+// Counter cuenta y muestrea LCS distintas a partir de la tabla DP sin
+// enumerarlas. Para cada celda (i, j) y cada letra c, una LCS de los prefijos
+// sec1[:i], sec2[:j] que termina en c puede incrustarse usando las últimas
+// ocurrencias de c en cada prefijo; así cada cadena distinta se cuenta una
+// sola vez. Complejidad O(n·m·σ) con σ = letras comunes.
+type Counter struct {
+	sec1, sec2 string
+	matriz     [][]int
+	alphabet   []byte
+	last1      [][]int // last1[i][k] = última posición < i de alphabet[k] en sec1 (-1 si no hay)
+	last2      [][]int
+	memo       map[int]*big.Int
+}
+
+// NewCounter prepara el conteo de LCS distintas para sec1, sec2 y su tabla DP.
+func NewCounter(sec1, sec2 string, matriz [][]int) *Counter {
+	var in1, in2 [256]bool
+	for i := 0; i < len(sec1); i++ {
+		in1[sec1[i]] = true
+	}
+	for j := 0; j < len(sec2); j++ {
+		in2[sec2[j]] = true
+	}
+	var alphabet []byte
+	for c := 0; c < 256; c++ {
+		if in1[c] && in2[c] {
+			alphabet = append(alphabet, byte(c))
+		}
+	}
+	return &Counter{
+		sec1:     sec1,
+		sec2:     sec2,
+		matriz:   matriz,
+		alphabet: alphabet,
+		last1:    lastOccurrences(sec1, alphabet),
+		last2:    lastOccurrences(sec2, alphabet),
+		memo:     make(map[int]*big.Int),
+	}
+}
+
+// lastOccurrences construye la tabla de últimas ocurrencias por prefijo.
+func lastOccurrences(s string, alphabet []byte) [][]int {
+	var idx [256]int
+	for k, c := range alphabet {
+		idx[c] = k + 1
+	}
+	out := make([][]int, len(s)+1)
+	row := make([]int, len(alphabet))
+	for k := range row {
+		row[k] = -1
+	}
+	out[0] = row
+	for i := 1; i <= len(s); i++ {
+		next := append([]int(nil), out[i-1]...)
+		if k := idx[s[i-1]]; k > 0 {
+			next[k-1] = i - 1
+		}
+		out[i] = next
+	}
+	return out
+}
+
+// count devuelve el número de LCS distintas de sec1[:i] y sec2[:j].
+func (c *Counter) count(i, j int) *big.Int {
+	if c.matriz[i][j] == 0 {
+		return big.NewInt(1)
+	}
+	key := i*(len(c.sec2)+1) + j
+	if v, ok := c.memo[key]; ok {
+		return v
+	}
+	total := new(big.Int)
+	for k := range c.alphabet {
+		p, q := c.last1[i][k], c.last2[j][k]
+		if p < 0 || q < 0 || c.matriz[p][q]+1 != c.matriz[i][j] {
+			continue
+		}
+		total.Add(total, c.count(p, q))
+	}
+	c.memo[key] = total
+	return total
+}
+
+// Count devuelve el número de LCS distintas (1 si la LCS es vacía).
+func (c *Counter) Count() *big.Int {
+	return new(big.Int).Set(c.count(len(c.sec1), len(c.sec2)))
+}
+
+// Sample devuelve una LCS elegida uniformemente entre todas las distintas.
+func (c *Counter) Sample(r *rand.Rand) string {
+	i, j := len(c.sec1), len(c.sec2)
+	out := make([]byte, c.matriz[i][j])
+	for pos := len(out) - 1; pos >= 0; pos-- {
+		// Elegir la última letra con probabilidad proporcional a sus LCS
+		pick := new(big.Int).Rand(r, c.count(i, j))
+		for k, ch := range c.alphabet {
+			p, q := c.last1[i][k], c.last2[j][k]
+			if p < 0 || q < 0 || c.matriz[p][q]+1 != c.matriz[i][j] {
+				continue
+			}
+			w := c.count(p, q)
+			if pick.Cmp(w) < 0 {
+				out[pos] = ch
+				i, j = p, q
+				break
+			}
+			pick.Sub(pick, w)
+		}
+	}
+	return string(out)
+}
+
+// SampleDistinct devuelve hasta k LCS distintas muestreadas uniformemente.
+// Si hay k o menos LCS en total, conviene usar Backtracking directamente.
+func (c *Counter) SampleDistinct(k int, r *rand.Rand) []string {
+	if total := c.count(len(c.sec1), len(c.sec2)); total.IsInt64() && total.Int64() < int64(k) {
+		k = int(total.Int64())
+	}
+	seen := make(map[string]struct{}, k)
+	out := make([]string, 0, k)
+	// Con k mucho menor que el total casi no hay repeticiones; el límite de
+	// intentos evita ciclos largos cuando k se acerca al total.
+	for attempts := 0; len(out) < k && attempts < 20*k+100; attempts++ {
+		s := c.Sample(r)
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+		out = append(out, s)
+	}
+	return out
+}
+
+// CountDistinct devuelve el número de LCS distintas de sec1 y sec2 usando la
+// tabla DP, sin materializarlas como hace Backtracking.
+func CountDistinct(sec1, sec2 string, matriz [][]int) *big.Int {
+	return NewCounter(sec1, sec2, matriz).Count()
+}
@@ -0,0 +1,99 @@
+package lcs
+
+// bytesPerCell es el tamaño de cada celda de la tabla DP ([][]int).
+const bytesPerCell = 8
+
+// TableBytes estima la memoria (en bytes) que ocupa la tabla DP completa
+// de (n+1)x(m+1) que construyen DPTable y DPTableParallel.
+func TableBytes(n, m int) int64 {
+	return int64(n+1) * int64(m+1) * bytesPerCell
+}
+
+// FitsInBudget indica si la tabla DP completa para secuencias de largo n y m
+// cabe en el presupuesto de memoria indicado (en bytes). Un presupuesto <= 0
+// se interpreta como "sin límite".
+func FitsInBudget(n, m int, budget int64) bool {
+	if budget <= 0 {
+		return true
+	}
+	return TableBytes(n, m) <= budget
+}
+
+// lastRow calcula la última fila de la tabla LCS de sec1 vs sec2 usando solo
+// dos filas de memoria. row[j] = LCS(sec1, sec2[:j]).
+func lastRow(sec1, sec2 string) []int {
+	m := len(sec2)
+	prev := make([]int, m+1)
+	curr := make([]int, m+1)
+	for i := 1; i <= len(sec1); i++ {
+		for j := 1; j <= m; j++ {
+			if sec1[i-1] == sec2[j-1] {
+				curr[j] = prev[j-1] + 1
+			} else if prev[j] >= curr[j-1] {
+				curr[j] = prev[j]
+			} else {
+				curr[j] = curr[j-1]
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev
+}
+
+// LengthLinear calcula solo la longitud de la LCS usando memoria O(min(n,m)).
+func LengthLinear(sec1, sec2 string) int {
+	if len(sec2) > len(sec1) {
+		sec1, sec2 = sec2, sec1
+	}
+	return lastRow(sec1, sec2)[len(sec2)]
+}
+
+// Hirschberg devuelve la longitud de la LCS y una LCS (no todas) usando
+// memoria lineal. Es la alternativa a DPTable + Backtracking cuando la tabla
+// completa no cabe en memoria (cadenas completas en vez de segmentos).
+func Hirschberg(sec1, sec2 string) (int, string) {
+	out := make([]byte, 0, min(len(sec1), len(sec2)))
+	out = hirschberg(sec1, sec2, out)
+	return len(out), string(out)
+}
+
+// hirschberg agrega a out una LCS de sec1 vs sec2 (divide y vencerás).
+func hirschberg(sec1, sec2 string, out []byte) []byte {
+	n, m := len(sec1), len(sec2)
+	if n == 0 || m == 0 {
+		return out
+	}
+	if n == 1 {
+		for j := 0; j < m; j++ {
+			if sec2[j] == sec1[0] {
+				return append(out, sec1[0])
+			}
+		}
+		return out
+	}
+
+	// Dividir sec1 por la mitad y buscar el corte óptimo de sec2:
+	// LCS(sec1[:mid], sec2[:k]) + LCS(sec1[mid:], sec2[k:]) máximo.
+	mid := n / 2
+	left := lastRow(sec1[:mid], sec2)
+	right := lastRow(reverse(sec1[mid:]), reverse(sec2))
+
+	best, cut := -1, 0
+	for k := 0; k <= m; k++ {
+		if v := left[k] + right[m-k]; v > best {
+			best, cut = v, k
+		}
+	}
+
+	out = hirschberg(sec1[:mid], sec2[:cut], out)
+	return hirschberg(sec1[mid:], sec2[cut:], out)
+}
+
+// reverse invierte una cadena de bytes.
+func reverse(s string) string {
+	b := make([]byte, len(s))
+	for i := 0; i < len(s); i++ {
+		b[len(s)-1-i] = s[i]
+	}
+	return string(b)
+}
@@ -0,0 +1,250 @@
+package lcs
+
+import (
+	"math"
+	"sort"
+)
+
+// DefaultKWayCells es el máximo de celdas de la tabla k-dimensional para
+// calcular la subsecuencia común exacta (64 MB con celdas int32).
+const DefaultKWayCells = 1 << 24
+
+// KWayCells devuelve la cantidad de celdas de la tabla DP k-dimensional
+// (el producto de len(s)+1), o math.MaxInt64 si se desborda.
+func KWayCells(seqs []string) int64 {
+	cells := int64(1)
+	for _, s := range seqs {
+		d := int64(len(s) + 1)
+		if cells > math.MaxInt64/d {
+			return math.MaxInt64
+		}
+		cells *= d
+	}
+	return cells
+}
+
+// CommonSubsequence devuelve una subsecuencia común a todas las seqs. Si la
+// tabla k-dimensional cabe en maxCells (<= 0 usa DefaultKWayCells) el
+// resultado es una LCS exacta de las k secuencias y exact vale true; si no,
+// se usa un método progresivo (LCS de la más corta con la siguiente, y así
+// sucesivamente) que garantiza una subsecuencia común pero no la más larga.
+func CommonSubsequence(seqs []string, maxCells int64) (string, bool) {
+	switch len(seqs) {
+	case 0:
+		return "", true
+	case 1:
+		return seqs[0], true
+	}
+	if maxCells <= 0 {
+		maxCells = DefaultKWayCells
+	}
+	if KWayCells(seqs) <= maxCells {
+		return kwayExact(seqs), true
+	}
+	return kwayProgressive(seqs), false
+}
+
+// kwayExact llena la tabla DP k-dimensional (aplanada, en orden
+// lexicográfico de índices, así cada predecesor ya está calculado) y
+// reconstruye una LCS.
+func kwayExact(seqs []string) string {
+	k := len(seqs)
+	dims := make([]int, k)
+	stride := make([]int, k)
+	total := 1
+	for t := k - 1; t >= 0; t-- {
+		dims[t] = len(seqs[t]) + 1
+		stride[t] = total
+		total *= dims[t]
+	}
+	diag := 0
+	for _, s := range stride {
+		diag += s
+	}
+
+	table := make([]int32, total)
+	idx := make([]int, k)
+	for c := 0; c < total; c++ {
+		if c > 0 {
+			// avanzar el contador de índices
+			for t := k - 1; t >= 0; t-- {
+				idx[t]++
+				if idx[t] < dims[t] {
+					break
+				}
+				idx[t] = 0
+			}
+		}
+		if allEqualAt(seqs, idx) {
+			table[c] = table[c-diag] + 1
+			continue
+		}
+		var best int32
+		for t := 0; t < k; t++ {
+			if idx[t] > 0 && table[c-stride[t]] > best {
+				best = table[c-stride[t]]
+			}
+		}
+		table[c] = best
+	}
+
+	// Reconstrucción desde la última celda
+	c := total - 1
+	for t := range idx {
+		idx[t] = dims[t] - 1
+	}
+	out := make([]byte, table[c])
+	for pos := len(out) - 1; pos >= 0; {
+		if allEqualAt(seqs, idx) {
+			out[pos] = seqs[0][idx[0]-1]
+			pos--
+			c -= diag
+			for t := range idx {
+				idx[t]--
+			}
+			continue
+		}
+		for t := 0; t < k; t++ {
+			if idx[t] > 0 && table[c-stride[t]] == table[c] {
+				c -= stride[t]
+				idx[t]--
+				break
+			}
+		}
+	}
+	return string(out)
+}
+
+// allEqualAt indica si todos los índices son > 0 y las letras
+// seqs[t][idx[t]-1] coinciden.
+func allEqualAt(seqs []string, idx []int) bool {
+	if idx[0] == 0 {
+		return false
+	}
+	b := seqs[0][idx[0]-1]
+	for t := 1; t < len(seqs); t++ {
+		if idx[t] == 0 || seqs[t][idx[t]-1] != b {
+			return false
+		}
+	}
+	return true
+}
+
+// kwayProgressive pliega las secuencias de la más corta a la más larga con
+// Hirschberg: el resultado parcial es siempre subsecuencia común de las ya
+// procesadas.
+func kwayProgressive(seqs []string) string {
+	order := append([]string(nil), seqs...)
+	sort.SliceStable(order, func(a, b int) bool { return len(order[a]) < len(order[b]) })
+	cur := order[0]
+	for _, s := range order[1:] {
+		if cur == "" {
+			break
+		}
+		_, cur = Hirschberg(cur, s)
+	}
+	return cur
+}
+
+// Motif es un patrón común a varias secuencias.
+type Motif struct {
+	Pattern string
+	Members []int // índices (base 0) de las secuencias que lo contienen
+	Exact   bool  // true si es la LCS exacta del grupo con que se construyó
+}
+
+// Consensus busca los patrones más largos presentes en al menos minFraction
+// de las secuencias. Con minFraction >= 1 se calcula la subsecuencia común a
+// todas; si no, para cada secuencia semilla se toma el grupo de las q-1 más
+// parecidas (por longitud de LCS bit-paralela), con q = ceil(minFraction·n),
+// y se calcula la subsecuencia común del grupo. Cada patrón se verifica
+// contra todas las secuencias para obtener sus miembros. Se devuelven hasta
+// top patrones (<= 0 = todos) ordenados por longitud y luego por soporte.
+func Consensus(seqs []string, minFraction float64, top int, maxCells int64) []Motif {
+	n := len(seqs)
+	if n == 0 {
+		return nil
+	}
+	q := int(math.Ceil(minFraction * float64(n)))
+	q = max(1, min(q, n))
+
+	var groups [][]int
+	if q == n {
+		all := make([]int, n)
+		for i := range all {
+			all[i] = i
+		}
+		groups = append(groups, all)
+	} else {
+		// Longitudes de LCS por par (simétricas)
+		sim := make([][]int, n)
+		for i := range sim {
+			sim[i] = make([]int, n)
+		}
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				sim[i][j] = LengthBitParallel(seqs[i], seqs[j])
+				sim[j][i] = sim[i][j]
+			}
+		}
+		for seed := 0; seed < n; seed++ {
+			others := make([]int, 0, n-1)
+			for j := 0; j < n; j++ {
+				if j != seed {
+					others = append(others, j)
+				}
+			}
+			sort.SliceStable(others, func(a, b int) bool { return sim[seed][others[a]] > sim[seed][others[b]] })
+			groups = append(groups, append([]int{seed}, others[:q-1]...))
+		}
+	}
+
+	seen := make(map[string]bool)
+	var motifs []Motif
+	for _, g := range groups {
+		members := make([]string, len(g))
+		for k, i := range g {
+			members[k] = seqs[i]
+		}
+		pattern, exact := CommonSubsequence(members, maxCells)
+		if pattern == "" || seen[pattern] {
+			continue
+		}
+		seen[pattern] = true
+
+		m := Motif{Pattern: pattern, Exact: exact}
+		for i, s := range seqs {
+			if isSubsequence(pattern, s) {
+				m.Members = append(m.Members, i)
+			}
+		}
+		if len(m.Members) >= q {
+			motifs = append(motifs, m)
+		}
+	}
+
+	sort.SliceStable(motifs, func(a, b int) bool {
+		if len(motifs[a].Pattern) != len(motifs[b].Pattern) {
+			return len(motifs[a].Pattern) > len(motifs[b].Pattern)
+		}
+		if len(motifs[a].Members) != len(motifs[b].Members) {
+			return len(motifs[a].Members) > len(motifs[b].Members)
+		}
+		return motifs[a].Pattern < motifs[b].Pattern
+	})
+	if top > 0 && len(motifs) > top {
+		motifs = motifs[:top]
+	}
+	return motifs
+}
+
+// isSubsequence indica si s es subsecuencia de t.
+func isSubsequence(s, t string) bool {
+	k := 0
+	for i := 0; i < len(t) && k < len(s); i++ {
+		if t[i] == s[k] {
+			k++
+		}
+	}
+	return k == len(s)
+}
@@ -2,6 +2,8 @@ package lcs
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"runtime"
 	"sync"
 )
@@ -305,23 +307,28 @@ func BacktrackingParallel(sec1, sec2 string, matriz [][]int) []string {
 }
 
 func PrintDP(sec1, sec2 string, dp [][]int) {
-	fmt.Printf("     ")
+	FprintDP(os.Stdout, sec1, sec2, dp)
+}
+
+// FprintDP es PrintDP escribiendo en w.
+func FprintDP(w io.Writer, sec1, sec2 string, dp [][]int) {
+	fmt.Fprintf(w, "     ")
 	for j := 0; j < len(sec2); j++ {
-		fmt.Printf("  %c", sec2[j])
+		fmt.Fprintf(w, "  %c", sec2[j])
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 	for i := 0; i <= len(sec1); i++ {
 		if i == 0 {
-			fmt.Printf("  ")
+			fmt.Fprintf(w, "  ")
 		} else {
-			fmt.Printf("%c ", sec1[i-1])
+			fmt.Fprintf(w, "%c ", sec1[i-1])
 		}
 		for j := 0; j <= len(sec2); j++ {
-			fmt.Printf("%2d", dp[i][j])
+			fmt.Fprintf(w, "%2d", dp[i][j])
 			if j < len(sec2) {
-				fmt.Printf(" ")
+				fmt.Fprintf(w, " ")
 			}
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
 	}
 }
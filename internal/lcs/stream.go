@@ -0,0 +1,140 @@
+package lcs
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"time"
+)
+
+// Limits acota la enumeración incremental de LCS.
+type Limits struct {
+	MaxCount int           // máximo de LCS distintas a producir (0 = sin límite)
+	MaxTime  time.Duration // tiempo máximo de exploración (0 = sin límite)
+}
+
+var (
+	// ErrMaxCount indica que existían más LCS que Limits.MaxCount.
+	ErrMaxCount = errors.New("se alcanzó el máximo de LCS")
+	// ErrMaxTime indica que se agotó Limits.MaxTime antes de terminar.
+	ErrMaxTime = errors.New("se agotó el tiempo de exploración")
+)
+
+// checkEvery es cada cuántas celdas exploradas se revisan contexto y reloj.
+const checkEvery = 1024
+
+// Enumerator produce las LCS distintas de forma incremental, con el mismo
+// recorrido que Backtracking pero entregando cada cadena apenas se encuentra
+// y pudiendo detenerse por contexto, cantidad o tiempo.
+type Enumerator struct {
+	sec1, sec2 string
+	matriz     [][]int
+	limits     Limits
+	err        error
+}
+
+// NewEnumerator prepara la enumeración de las LCS de sec1 y sec2.
+func NewEnumerator(sec1, sec2 string, matriz [][]int, limits Limits) *Enumerator {
+	return &Enumerator{sec1: sec1, sec2: sec2, matriz: matriz, limits: limits}
+}
+
+// Err indica por qué se detuvo la última enumeración: nil si se exploró todo
+// (o si el consumidor cortó el range), ctx.Err(), ErrMaxCount o ErrMaxTime.
+func (e *Enumerator) Err() error {
+	return e.err
+}
+
+// Truncated indica si la última enumeración se cortó antes de terminar.
+func (e *Enumerator) Truncated() bool {
+	return e.err != nil
+}
+
+// All devuelve un iterador sobre las LCS distintas. Se detiene al cancelar
+// ctx o al alcanzar los límites; el motivo queda disponible en Err.
+func (e *Enumerator) All(ctx context.Context) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		e.err = nil
+
+		var deadline time.Time
+		if e.limits.MaxTime > 0 {
+			deadline = time.Now().Add(e.limits.MaxTime)
+		}
+
+		type cellKey struct{ i, j int }
+		type frame struct {
+			i, j    int
+			pattern string
+		}
+
+		visited := make(map[cellKey]map[string]bool)
+		results := make(map[string]struct{})
+		stack := []frame{{i: len(e.sec1), j: len(e.sec2)}}
+
+		for steps := 1; len(stack) > 0; steps++ {
+			if steps%checkEvery == 0 {
+				if err := ctx.Err(); err != nil {
+					e.err = err
+					return
+				}
+				if !deadline.IsZero() && time.Now().After(deadline) {
+					e.err = ErrMaxTime
+					return
+				}
+			}
+
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			k := cellKey{i: current.i, j: current.j}
+			if visited[k] == nil {
+				visited[k] = make(map[string]bool)
+			}
+			if visited[k][current.pattern] {
+				continue
+			}
+			visited[k][current.pattern] = true
+
+			// Caso base: LCS completa
+			if e.matriz[current.i][current.j] == 0 {
+				if _, seen := results[current.pattern]; seen {
+					continue
+				}
+				if e.limits.MaxCount > 0 && len(results) >= e.limits.MaxCount {
+					e.err = ErrMaxCount
+					return
+				}
+				results[current.pattern] = struct{}{}
+				if !yield(current.pattern) {
+					return
+				}
+				continue
+			}
+
+			if e.sec1[current.i-1] == e.sec2[current.j-1] {
+				stack = append(stack, frame{
+					i:       current.i - 1,
+					j:       current.j - 1,
+					pattern: string(e.sec1[current.i-1]) + current.pattern,
+				})
+				continue
+			}
+
+			if e.matriz[current.i-1][current.j] == e.matriz[current.i][current.j] {
+				stack = append(stack, frame{i: current.i - 1, j: current.j, pattern: current.pattern})
+			}
+			if e.matriz[current.i][current.j-1] == e.matriz[current.i][current.j] {
+				stack = append(stack, frame{i: current.i, j: current.j - 1, pattern: current.pattern})
+			}
+		}
+	}
+}
+
+// Collect consume el iterador y devuelve las LCS encontradas junto con el
+// motivo de corte (nil si la enumeración fue completa).
+func (e *Enumerator) Collect(ctx context.Context) ([]string, error) {
+	var out []string
+	for s := range e.All(ctx) {
+		out = append(out, s)
+	}
+	return out, e.err
+}
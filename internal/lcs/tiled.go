@@ -0,0 +1,92 @@
+package lcs
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultTileSize es el lado de bloque por defecto para DPTableTiled.
+const DefaultTileSize = 64
+
+// DPTableTiled construye la tabla LCS con un frente de onda por bloques: la
+// tabla se divide en bloques de tile x tile celdas, cada worker calcula un
+// bloque completo de forma secuencial y los bloques de una misma
+// anti-diagonal (independientes entre sí) se reparten entre los workers.
+// Así la sincronización es por bloque y no por celda como en DPTableParallel.
+// Un tile <= 0 usa DefaultTileSize.
+func DPTableTiled(sec1, sec2 string, tile int) [][]int {
+	if tile <= 0 {
+		tile = DefaultTileSize
+	}
+	n, m := len(sec1), len(sec2)
+	dp := make([][]int, n+1)
+	for i := range dp {
+		dp[i] = make([]int, m+1)
+	}
+	if n == 0 || m == 0 {
+		return dp
+	}
+
+	rows := (n + tile - 1) / tile
+	cols := (m + tile - 1) / tile
+
+	numWorkers := runtime.GOMAXPROCS(0)
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+
+	// computeTile calcula el bloque (r, c) fila por fila
+	computeTile := func(r, c int) {
+		iEnd := min((r+1)*tile, n)
+		jStart, jEnd := c*tile+1, min((c+1)*tile, m)
+		for i := r*tile + 1; i <= iEnd; i++ {
+			prev, curr := dp[i-1], dp[i]
+			a := sec1[i-1]
+			for j := jStart; j <= jEnd; j++ {
+				if a == sec2[j-1] {
+					curr[j] = prev[j-1] + 1
+				} else if prev[j] >= curr[j-1] {
+					curr[j] = prev[j]
+				} else {
+					curr[j] = curr[j-1]
+				}
+			}
+		}
+	}
+
+	// Recorremos las anti-diagonales de bloques r+c = d
+	for d := 0; d <= rows+cols-2; d++ {
+		rStart := max(0, d-cols+1)
+		rEnd := min(d, rows-1)
+		count := rEnd - rStart + 1
+
+		if count == 1 || numWorkers == 1 {
+			for r := rStart; r <= rEnd; r++ {
+				computeTile(r, d-r)
+			}
+			continue
+		}
+
+		// Los workers toman bloques de la diagonal con un contador atómico
+		var next atomic.Int64
+		var wg sync.WaitGroup
+		for w := 0; w < min(numWorkers, count); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					k := int(next.Add(1)) - 1
+					if k >= count {
+						return
+					}
+					r := rStart + k
+					computeTile(r, d-r)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	return dp
+}
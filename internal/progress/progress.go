@@ -0,0 +1,267 @@
+// Package progress reporta el avance de una corrida larga (pares completados,
+// velocidad, ETA y el par en curso más lento) en stderr: como barra si es una
+// terminal, como líneas periódicas si no, o como eventos JSON (una línea por
+// evento) para que otro programa los consuma.
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Modos de reporte.
+const (
+	ModeAuto  = "auto"  // barra si w es una terminal, líneas si no
+	ModeBar   = "bar"   // barra que se reescribe con \r
+	ModePlain = "plain" // una línea cada cierto tiempo
+	ModeJSON  = "json"  // eventos JSON Lines
+	ModeNone  = "none"  // sin reporte
+)
+
+// Intervalos por defecto entre actualizaciones según el modo.
+var intervals = map[string]time.Duration{
+	ModeBar:   200 * time.Millisecond,
+	ModePlain: 10 * time.Second,
+	ModeJSON:  time.Second,
+}
+
+// Tipos de evento.
+const (
+	EventStart    = "start"
+	EventProgress = "progress"
+	EventDone     = "done"
+)
+
+// Event es una foto del avance; en ModeJSON se escribe una por línea.
+type Event struct {
+	Event          string    `json:"event"`
+	Done           int       `json:"done"`
+	Total          int       `json:"total"`
+	Rate           float64   `json:"rate"`            // pares por segundo (sin contar los reanudados)
+	ETASeconds     float64   `json:"eta_s,omitempty"` // 0 si no se puede estimar
+	ElapsedSeconds float64   `json:"elapsed_s"`
+	Slowest        *InFlight `json:"slowest,omitempty"`
+}
+
+// InFlight es un trabajo en curso.
+type InFlight struct {
+	Label          string  `json:"label"`
+	ElapsedSeconds float64 `json:"elapsed_s"`
+}
+
+type running struct {
+	label string
+	start time.Time
+}
+
+// Reporter acumula el avance y lo escribe periódicamente. Un *Reporter nil
+// es válido y no hace nada (ModeNone).
+type Reporter struct {
+	mu       sync.Mutex
+	w        io.Writer
+	mode     string
+	total    int
+	done     int
+	resumed  int
+	start    time.Time
+	inflight map[int]running
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// IsTerminal indica si f es una terminal (dispositivo de caracteres).
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// CheckMode valida el nombre de un modo.
+func CheckMode(mode string) error {
+	switch mode {
+	case ModeAuto, ModeBar, ModePlain, ModeJSON, ModeNone, "":
+		return nil
+	}
+	return fmt.Errorf("modo de progreso desconocido %q (use auto, bar, plain, json o none)", mode)
+}
+
+// New crea un reporter para total trabajos que escribe en f. Devuelve nil
+// con ModeNone.
+func New(f *os.File, mode string, total int) (*Reporter, error) {
+	if err := CheckMode(mode); err != nil {
+		return nil, err
+	}
+	switch mode {
+	case ModeNone:
+		return nil, nil
+	case ModeAuto, "":
+		mode = ModePlain
+		if IsTerminal(f) {
+			mode = ModeBar
+		}
+	}
+	return &Reporter{w: f, mode: mode, total: total, inflight: make(map[int]running)}, nil
+}
+
+// Resumed marca n trabajos como ya hechos (por ejemplo, recuperados de un
+// journal); cuentan como completados pero no para la velocidad.
+func (r *Reporter) Resumed(n int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.done += n
+	r.resumed += n
+	r.mu.Unlock()
+}
+
+// Start emite el evento inicial y empieza las actualizaciones periódicas.
+func (r *Reporter) Start() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.start = time.Now()
+	r.mu.Unlock()
+	r.emit(EventStart)
+
+	r.stop = make(chan struct{})
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(intervals[r.mode])
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				r.emit(EventProgress)
+			}
+		}
+	}()
+}
+
+// Begin registra que empezó el trabajo id.
+func (r *Reporter) Begin(id int, label string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.inflight[id] = running{label: label, start: time.Now()}
+	r.mu.Unlock()
+}
+
+// End registra que terminó el trabajo id.
+func (r *Reporter) End(id int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	delete(r.inflight, id)
+	r.done++
+	r.mu.Unlock()
+}
+
+// Finish detiene las actualizaciones y emite el evento final.
+func (r *Reporter) Finish() {
+	if r == nil {
+		return
+	}
+	if r.stop != nil {
+		close(r.stop)
+		r.wg.Wait()
+	}
+	r.emit(EventDone)
+}
+
+// Snapshot devuelve el estado actual como un evento de progreso.
+func (r *Reporter) Snapshot() Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.snapshot(EventProgress, time.Now())
+}
+
+func (r *Reporter) snapshot(kind string, now time.Time) Event {
+	elapsed := now.Sub(r.start).Seconds()
+	e := Event{Event: kind, Done: r.done, Total: r.total, ElapsedSeconds: elapsed}
+	if computed := r.done - r.resumed; computed > 0 && elapsed > 0 {
+		e.Rate = float64(computed) / elapsed
+		e.ETASeconds = float64(r.total-r.done) / e.Rate
+	}
+	for _, run := range r.inflight {
+		d := now.Sub(run.start).Seconds()
+		if e.Slowest == nil || d > e.Slowest.ElapsedSeconds {
+			e.Slowest = &InFlight{Label: run.label, ElapsedSeconds: d}
+		}
+	}
+	return e
+}
+
+// emit escribe un evento según el modo.
+func (r *Reporter) emit(kind string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := r.snapshot(kind, time.Now())
+
+	switch r.mode {
+	case ModeJSON:
+		line, _ := json.Marshal(e)
+		fmt.Fprintf(r.w, "%s\n", line)
+	case ModeBar:
+		fmt.Fprintf(r.w, "\r%s %s\033[K", bar(e.Done, e.Total, 20), e)
+		if kind == EventDone {
+			fmt.Fprintln(r.w)
+		}
+	default:
+		if kind != EventStart {
+			fmt.Fprintf(r.w, "[progreso] %s\n", e)
+		}
+	}
+}
+
+// String resume el evento en una línea, ej.
+// "120/6000 (2.0%) | 15.3 pares/s | ETA 6m24s | más lento: 1 vs 9 (12s)".
+func (e Event) String() string {
+	var b strings.Builder
+	pct := 0.0
+	if e.Total > 0 {
+		pct = float64(e.Done) / float64(e.Total) * 100
+	}
+	fmt.Fprintf(&b, "%d/%d (%.1f%%) | %.1f pares/s", e.Done, e.Total, pct, e.Rate)
+	if e.Event == EventDone {
+		fmt.Fprintf(&b, " | total %s", seconds(e.ElapsedSeconds))
+	} else if e.ETASeconds > 0 {
+		fmt.Fprintf(&b, " | ETA %s", seconds(e.ETASeconds))
+	}
+	if e.Slowest != nil {
+		fmt.Fprintf(&b, " | más lento: %s (%s)", e.Slowest.Label, seconds(e.Slowest.ElapsedSeconds))
+	}
+	return b.String()
+}
+
+// ParseEvent interpreta una línea de ModeJSON; false si no es un evento.
+func ParseEvent(line []byte) (Event, bool) {
+	var e Event
+	if err := json.Unmarshal(line, &e); err != nil || e.Event == "" {
+		return Event{}, false
+	}
+	return e, true
+}
+
+func seconds(s float64) string {
+	return (time.Duration(s) * time.Second).String()
+}
+
+func bar(done, total, width int) string {
+	filled := width
+	if total > 0 {
+		filled = done * width / total
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", width-filled) + "]"
+}
@@ -0,0 +1,279 @@
+// Package scan busca patrones PROSITE (los que genera batchcompare, ej.
+// C-x(4)-C-x(1)-K-x(10)-H) en secuencias y reporta cada coincidencia con sus
+// posiciones y la longitud real de cada gap.
+//
+// La semántica por defecto es la del motor de gaps
+// (gaps.AllGapValuesDistanceTotalViable): los residuos, clases y exclusiones
+// del patrón solo coinciden con residuos en mayúsculas (los que interactúan),
+// y un gap x(n) es la distancia total entre dos posiciones, así que cuenta
+// cualquier residuo, en mayúscula o minúscula. CompileMatch acepta las otras
+// semánticas de gaps (strict, relaxed, interaction).
+package scan
+
+import (
+	"github.com/lucckkas/patternfinder/internal/gaps"
+	"github.com/lucckkas/patternfinder/internal/utils"
+)
+
+// Match es una coincidencia de un patrón en una secuencia.
+type Match struct {
+	Start, End int    // residuos seq[Start:End] (base 0, End excluido)
+	Residues   string // seq[Start:End]
+	Gaps       []int  // longitud de cada x del patrón, en orden
+}
+
+// element es un elemento del patrón compilado.
+type element struct {
+	accept   [256]bool // residuos aceptados
+	any      bool      // x: se registra su longitud en Match.Gaps
+	cterm    bool      // [G>]: también coincide con el final de la secuencia
+	min, max int
+}
+
+// Matcher es un patrón compilado. Es seguro usarlo desde varias goroutines.
+type Matcher struct {
+	pattern   gaps.Pattern
+	semantics string // gaps.Match*
+	elements  []element
+	minLen    []int // minLen[i] = residuos mínimos que consumen elements[i:]
+	gaps      int   // cantidad de x
+}
+
+// Compile compila un patrón con la sintaxis de gaps.ParsePattern y la
+// semántica gaps.MatchDefault.
+func Compile(pattern string) (*Matcher, error) {
+	return CompileMatch(pattern, gaps.MatchDefault)
+}
+
+// CompileMatch compila un patrón con la semántica de coincidencia match:
+//
+//	default      residuos del patrón en mayúsculas; x cuenta cualquier residuo
+//	strict       además, los x solo pueden ser minúsculas
+//	relaxed      los residuos del patrón coinciden en cualquier caso
+//	interaction  los x cuentan solo mayúsculas (las minúsculas se saltean)
+func CompileMatch(pattern, match string) (*Matcher, error) {
+	if err := gaps.CheckMatch(match); err != nil {
+		return nil, err
+	}
+	if match == "" {
+		match = gaps.MatchDefault
+	}
+	pat, err := gaps.ParsePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	relaxed := match == gaps.MatchRelaxed
+	m := &Matcher{pattern: pat, semantics: match, elements: make([]element, len(pat.Elements))}
+	for i, el := range pat.Elements {
+		e := element{any: el.Kind == gaps.Any, cterm: el.CTerm, min: el.Min, max: el.Max}
+		switch el.Kind {
+		case gaps.Any:
+			for c := range e.accept {
+				e.accept[c] = match != gaps.MatchStrict || !isUpper(byte(c))
+			}
+			m.gaps++
+		case gaps.Residue:
+			for j := 0; j < len(el.Residues); j++ {
+				e.accept[el.Residues[j]] = true
+				if relaxed {
+					e.accept[lower(el.Residues[j])] = true
+				}
+			}
+		case gaps.Exclude:
+			for c := byte('A'); c <= 'Z'; c++ {
+				e.accept[c] = true
+				if relaxed {
+					e.accept[lower(c)] = true
+				}
+			}
+			for j := 0; j < len(el.Residues); j++ {
+				e.accept[el.Residues[j]] = false
+				e.accept[lower(el.Residues[j])] = false
+			}
+		}
+		m.elements[i] = e
+	}
+
+	// Un [G>] puede coincidir con el final sin consumir residuos
+	m.minLen = make([]int, len(m.elements)+1)
+	for i := len(m.elements) - 1; i >= 0; i-- {
+		need := m.elements[i].min
+		if m.elements[i].cterm {
+			need = 0
+		}
+		m.minLen[i] = m.minLen[i+1] + need
+	}
+	return m, nil
+}
+
+// String devuelve el patrón en forma canónica.
+func (m *Matcher) String() string {
+	return m.pattern.String()
+}
+
+// Pattern devuelve el patrón compilado.
+func (m *Matcher) Pattern() gaps.Pattern {
+	return m.pattern
+}
+
+// Semantics devuelve la semántica de coincidencia (gaps.Match*).
+func (m *Matcher) Semantics() string {
+	return m.semantics
+}
+
+func isUpper(c byte) bool { return c >= 'A' && c <= 'Z' }
+
+func lower(c byte) byte { return c + 'a' - 'A' }
+
+// Scan llama a fn con cada coincidencia de seq, ordenadas por posición de
+// inicio y luego por longitud de los elementos (de menor a mayor). Se
+// reportan todas las formas de hacer coincidir el patrón, incluidas las que
+// se solapan; fn devuelve false para detener la búsqueda.
+func (m *Matcher) Scan(seq string, fn func(Match) bool) {
+	if m.semantics == gaps.MatchInteraction {
+		m.scanInteraction(seq, fn)
+		return
+	}
+	m.scan(seq, fn)
+}
+
+// scanInteraction busca en la proyección a mayúsculas (así los x cuentan
+// solo mayúsculas) y traduce las coincidencias a posiciones de seq.
+func (m *Matcher) scanInteraction(seq string, fn func(Match) bool) {
+	p := utils.Project(seq)
+	m.scan(p.Upper, func(mt Match) bool {
+		mt.Start = p.OriginalPos(mt.Start)
+		mt.End = p.OriginalPos(mt.End-1) + 1
+		mt.Residues = seq[mt.Start:mt.End]
+		return fn(mt)
+	})
+}
+
+func (m *Matcher) scan(seq string, fn func(Match) bool) {
+	n := len(seq)
+	last := n - m.minLen[0]
+	if m.pattern.NTerm && last > 0 {
+		last = 0
+	}
+	s := &scanState{
+		seq:  seq,
+		fn:   fn,
+		lens: make([]int, 0, m.gaps),
+		dead: make([]bool, (len(m.elements)+1)*(n+1)),
+	}
+	for start := 0; start <= last; start++ {
+		// Descarte rápido por el primer elemento
+		if first := &m.elements[0]; first.min > 0 && start < n && !first.accept[seq[start]] {
+			continue
+		}
+		s.start = start
+		if !m.match(s, 0, start, s.lens) {
+			return
+		}
+	}
+}
+
+// scanState es el estado de una búsqueda sobre una secuencia.
+type scanState struct {
+	seq     string
+	start   int
+	fn      func(Match) bool
+	lens    []int
+	emitted int
+	// dead[i*(n+1)+pos]: desde elements[i] en pos no hay coincidencias. No
+	// depende del inicio, así que se reutiliza entre inicios y evita la
+	// explosión de combinaciones de varios x(n,m) seguidos sin coincidencia.
+	dead []bool
+}
+
+// match prueba elements[i:] desde pos; devuelve false si fn pidió detenerse.
+func (m *Matcher) match(s *scanState, i, pos int, lens []int) bool {
+	n := len(s.seq)
+	if i == len(m.elements) {
+		if pos == s.start || (m.pattern.CTerm && pos != n) {
+			return true
+		}
+		s.emitted++
+		return s.fn(Match{
+			Start:    s.start,
+			End:      pos,
+			Residues: s.seq[s.start:pos],
+			Gaps:     append([]int(nil), lens...),
+		})
+	}
+	state := i*(n+1) + pos
+	if s.dead[state] {
+		return true
+	}
+	before := s.emitted
+
+	e := &m.elements[i]
+	if e.cterm && pos == n {
+		// [G>] al final de la secuencia
+		if !m.match(s, i+1, pos, lens) {
+			return false
+		}
+	} else {
+		// Consumir e.min residuos y luego probar cada repetición hasta e.max
+		k := 0
+		for k < e.min && pos+k < n && e.accept[s.seq[pos+k]] {
+			k++
+		}
+		if k == e.min {
+			for ; k <= e.max && pos+k+m.minLen[i+1] <= n; k++ {
+				next := lens
+				if e.any {
+					next = append(lens, k)
+				}
+				if !m.match(s, i+1, pos+k, next) {
+					return false
+				}
+				if pos+k >= n || !e.accept[s.seq[pos+k]] {
+					break
+				}
+			}
+		}
+	}
+
+	// Con pos == inicio la coincidencia vacía se descarta, así que el estado
+	// podría tener coincidencias desde otro inicio
+	if s.emitted == before && pos != s.start {
+		s.dead[state] = true
+	}
+	return true
+}
+
+// FindAll devuelve las coincidencias de seq (ver Scan), como mucho limit
+// (<= 0 = todas). truncated indica que había más.
+func (m *Matcher) FindAll(seq string, limit int) (matches []Match, truncated bool) {
+	m.Scan(seq, func(mt Match) bool {
+		if limit > 0 && len(matches) == limit {
+			truncated = true
+			return false
+		}
+		matches = append(matches, mt)
+		return true
+	})
+	return matches, truncated
+}
+
+// Matches indica si el patrón aparece en seq.
+func (m *Matcher) Matches(seq string) bool {
+	found := false
+	m.Scan(seq, func(Match) bool {
+		found = true
+		return false
+	})
+	return found
+}
+
+// Support cuenta las secuencias de seqs en las que aparece el patrón.
+func (m *Matcher) Support(seqs []string) int {
+	n := 0
+	for _, s := range seqs {
+		if m.Matches(s) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,40 @@
+package seqio
+
+import "github.com/lucckkas/patternfinder/internal/utils"
+
+// Criterios de deduplicación.
+const (
+	DedupNone  = "none"  // comparar todas las secuencias
+	DedupExact = "exact" // agrupar secuencias idénticas
+	DedupUpper = "upper" // agrupar secuencias con las mismas mayúsculas (aproximado)
+)
+
+// Group es un conjunto de secuencias equivalentes representado por la
+// primera de ellas. Los índices son posiciones en el slice de entrada.
+type Group struct {
+	Rep     int
+	Members []int // incluye a Rep, en orden de aparición
+}
+
+// Dedup agrupa las secuencias equivalentes según mode (ver Dedup*). Los
+// grupos quedan en el orden de aparición de su representante; con DedupNone
+// cada secuencia es su propio grupo.
+func Dedup(seqs []string, mode string) []Group {
+	key := func(s string) string { return s }
+	if mode == DedupUpper {
+		key = utils.UpperOnly
+	}
+
+	var groups []Group
+	index := make(map[string]int) // clave → posición en groups
+	for i, s := range seqs {
+		k := key(s)
+		if g, ok := index[k]; ok && mode != DedupNone {
+			groups[g].Members = append(groups[g].Members, i)
+			continue
+		}
+		index[k] = len(groups)
+		groups = append(groups, Group{Rep: i, Members: []int{i}})
+	}
+	return groups
+}
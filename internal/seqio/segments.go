@@ -0,0 +1,132 @@
+package seqio
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Segments es la estructura de Segmentos.json generada por Interactions.py:
+// proteína → instancia de ligando ("CODIGO_CADENA_NUM", ej. "ZN_A_201") →
+// segmentos de la secuencia que interactúan con él.
+type Segments map[string]map[string][]string
+
+// readSegments lee un Segmentos.json. Cada segmento no vacío es un Record con
+// ID "proteína/ligando/n"; el orden es determinista (proteínas y ligandos
+// ordenados, segmentos en el orden del archivo).
+func readSegments(r io.Reader) ([]Record, error) {
+	var segs Segments
+	if err := json.NewDecoder(r).Decode(&segs); err != nil {
+		return nil, fmt.Errorf("JSON de segmentos inválido: %w", err)
+	}
+
+	proteins := make([]string, 0, len(segs))
+	for p := range segs {
+		proteins = append(proteins, p)
+	}
+	sort.Strings(proteins)
+
+	var records []Record
+	for _, protein := range proteins {
+		ligands := make([]string, 0, len(segs[protein]))
+		for l := range segs[protein] {
+			ligands = append(ligands, l)
+		}
+		sort.Strings(ligands)
+		for _, ligand := range ligands {
+			for k, seq := range segs[protein][ligand] {
+				seq = strings.TrimSpace(seq)
+				if seq == "" {
+					continue
+				}
+				records = append(records, Record{
+					ID:      protein + "/" + ligand + "/" + strconv.Itoa(k+1),
+					Seq:     seq,
+					Protein: protein,
+					Ligand:  ligand,
+					Segment: k + 1,
+				})
+			}
+		}
+	}
+	return records, nil
+}
+
+// LigandCode devuelve el código del ligando de un ID "CODIGO_CADENA_NUM".
+func LigandCode(ligand string) string {
+	code, _, _ := strings.Cut(ligand, "_")
+	return code
+}
+
+// LigandChain devuelve la cadena de un ID "CODIGO_CADENA_NUM" ("" si no tiene).
+func LigandChain(ligand string) string {
+	parts := strings.Split(ligand, "_")
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[1]
+}
+
+// Filter selecciona segmentos por ligando, proteína y cadena. Los campos
+// vacíos no filtran; las comparaciones no distinguen mayúsculas.
+type Filter struct {
+	Ligand   string   // prefijo del código de ligando, ej. "ZN"
+	Proteins []string // proteínas a incluir, ej. "1a1f"
+	Chains   []string // cadenas del ligando a incluir, ej. "A"
+}
+
+// Empty indica si el filtro no selecciona nada en particular.
+func (f Filter) Empty() bool {
+	return f.Ligand == "" && len(f.Proteins) == 0 && len(f.Chains) == 0
+}
+
+// Match indica si un segmento pasa el filtro.
+func (f Filter) Match(r Record) bool {
+	if f.Ligand != "" && !strings.HasPrefix(strings.ToUpper(LigandCode(r.Ligand)), strings.ToUpper(f.Ligand)) {
+		return false
+	}
+	if len(f.Proteins) > 0 && !containsFold(f.Proteins, r.Protein) {
+		return false
+	}
+	if len(f.Chains) > 0 && !containsFold(f.Chains, LigandChain(r.Ligand)) {
+		return false
+	}
+	return true
+}
+
+// Apply devuelve los registros que pasan el filtro.
+func (f Filter) Apply(records []Record) []Record {
+	if f.Empty() {
+		return records
+	}
+	var out []Record
+	for _, r := range records {
+		if f.Match(r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// SplitList separa una lista por comas ignorando espacios y elementos vacíos.
+func SplitList(s string) []string {
+	var out []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func containsFold(list []string, s string) bool {
+	for _, x := range list {
+		if strings.EqualFold(x, s) {
+			return true
+		}
+	}
+	return false
+}
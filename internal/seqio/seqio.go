@@ -0,0 +1,170 @@
+// Package seqio lee archivos de secuencias en texto plano (una por línea),
+// FASTA / multi-FASTA o el JSON de segmentos de Interactions.py
+// (Segmentos.json), detectando el formato automáticamente.
+package seqio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Format es el formato detectado de un archivo de secuencias.
+type Format int
+
+const (
+	FormatPlain    Format = iota // una secuencia por línea
+	FormatFASTA                  // registros ">id descripción" + líneas de secuencia
+	FormatSegments               // JSON proteína → ligando → segmentos
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatFASTA:
+		return "FASTA"
+	case FormatSegments:
+		return "Segmentos JSON"
+	}
+	return "texto plano"
+}
+
+// Record es una secuencia con su identificador. En FASTA, ID es la primera
+// palabra de la cabecera y Description el resto; en texto plano, ID es el
+// número de secuencia (base 1) y Description queda vacía. Los segmentos de
+// Segmentos.json guardan además de qué proteína y ligando provienen.
+type Record struct {
+	ID          string
+	Description string
+	Seq         string
+
+	Protein string // proteína (ej. "1a1f"), solo en segmentos
+	Ligand  string // instancia del ligando (ej. "ZN_A_201"), solo en segmentos
+	Segment int    // número de segmento dentro del ligando (base 1), solo en segmentos
+}
+
+// ReadFile lee un archivo de secuencias (ver Read).
+func ReadFile(filename string) ([]Record, Format, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, FormatPlain, err
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+// Read lee secuencias detectando el formato: si el contenido empieza con '{'
+// se lee como Segmentos.json (ver readSegments); si la primera línea con
+// contenido (ignorando vacías y comentarios '#') empieza con '>', se lee como
+// FASTA; si no, como texto plano con una secuencia por línea. En FASTA las
+// secuencias pueden ocupar varias líneas (se concatenan sin espacios) y las
+// líneas que empiezan con ';' son comentarios.
+func Read(r io.Reader) ([]Record, Format, error) {
+	br := bufio.NewReader(r)
+	if first, err := firstNonSpace(br); err == nil && first == '{' {
+		records, err := readSegments(br)
+		return records, FormatSegments, err
+	}
+
+	scanner := bufio.NewScanner(br)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var (
+		records []Record
+		format  = FormatPlain
+		known   bool // formato ya detectado
+		seq     strings.Builder
+	)
+	// flush cierra el registro FASTA en curso
+	flush := func() error {
+		if len(records) == 0 {
+			return nil
+		}
+		last := &records[len(records)-1]
+		last.Seq = seq.String()
+		seq.Reset()
+		if last.Seq == "" {
+			return fmt.Errorf("registro FASTA %q sin secuencia", last.ID)
+		}
+		return nil
+	}
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		// Ignorar líneas vacías y comentarios
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !known {
+			known = true
+			if strings.HasPrefix(line, ">") {
+				format = FormatFASTA
+			}
+		}
+
+		if format == FormatPlain {
+			records = append(records, Record{ID: strconv.Itoa(len(records) + 1), Seq: line})
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, ">"):
+			if err := flush(); err != nil {
+				return nil, format, err
+			}
+			header := strings.TrimSpace(line[1:])
+			id, desc, _ := strings.Cut(header, " ")
+			if id == "" {
+				return nil, format, fmt.Errorf("línea %d: cabecera FASTA sin identificador", lineNumber)
+			}
+			records = append(records, Record{ID: id, Description: strings.TrimSpace(desc)})
+		default:
+			// Las secuencias pueden venir partidas en varias líneas
+			for _, f := range strings.Fields(line) {
+				seq.WriteString(f)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, format, err
+	}
+	if format == FormatFASTA {
+		if err := flush(); err != nil {
+			return nil, format, err
+		}
+	}
+	return records, format, nil
+}
+
+// Seqs devuelve solo las secuencias de los registros.
+func Seqs(records []Record) []string {
+	out := make([]string, len(records))
+	for i, r := range records {
+		out[i] = r.Seq
+	}
+	return out
+}
+
+// firstNonSpace descarta los espacios iniciales y devuelve (sin consumirlo)
+// el primer byte con contenido.
+func firstNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			br.ReadByte()
+		default:
+			return b[0], nil
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package utils
+
+// Projection relaciona una secuencia original (mayúsculas = residuos que
+// interactúan, minúsculas = resto) con su proyección a mayúsculas, que es lo
+// que se compara con LCS. Mantiene los mapas de índices en ambos sentidos
+// para traducir resultados sobre Upper a posiciones de residuos en Original.
+type Projection struct {
+	Original string
+	Upper    string
+
+	toOriginal []int // toOriginal[k] = índice en Original de Upper[k]
+	toUpper    []int // toUpper[i] = índice en Upper de Original[i], -1 si es minúscula
+}
+
+// Project construye la proyección a mayúsculas de s.
+func Project(s string) *Projection {
+	p := &Projection{
+		Original:   s,
+		toOriginal: make([]int, 0, len(s)),
+		toUpper:    make([]int, len(s)),
+	}
+	upper := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' {
+			p.toUpper[i] = len(upper)
+			p.toOriginal = append(p.toOriginal, i)
+			upper = append(upper, c)
+		} else {
+			p.toUpper[i] = -1
+		}
+	}
+	p.Upper = string(upper)
+	return p
+}
+
+// Len devuelve la cantidad de mayúsculas.
+func (p *Projection) Len() int {
+	return len(p.Upper)
+}
+
+// OriginalPos traduce un índice de Upper (base 0) a su índice en Original.
+func (p *Projection) OriginalPos(k int) int {
+	return p.toOriginal[k]
+}
+
+// UpperPos traduce un índice de Original a su índice en Upper (-1 si el
+// residuo es minúscula).
+func (p *Projection) UpperPos(i int) int {
+	return p.toUpper[i]
+}
+
+// ToOriginal devuelve el mapa completo Upper -> Original (no modificar).
+func (p *Projection) ToOriginal() []int {
+	return p.toOriginal
+}
+
+// OriginalPositions traduce una lista de índices de Upper a Original.
+func (p *Projection) OriginalPositions(idx []int) []int {
+	out := make([]int, len(idx))
+	for n, k := range idx {
+		out[n] = p.toOriginal[k]
+	}
+	return out
+}
+
+// Distance devuelve la cantidad de residuos (de cualquier caso) que hay en
+// Original entre las mayúsculas k1 < k2 de Upper. Es la misma distancia b-a-1
+// que usa gaps.AllGapValuesDistanceTotalViable.
+func (p *Projection) Distance(k1, k2 int) int {
+	return p.toOriginal[k2] - p.toOriginal[k1] - 1
+}
+
+// Gaps devuelve las distancias en Original entre índices consecutivos de Upper.
+func (p *Projection) Gaps(idx []int) []int {
+	if len(idx) < 2 {
+		return []int{}
+	}
+	out := make([]int, len(idx)-1)
+	for n := 0; n+1 < len(idx); n++ {
+		out[n] = p.Distance(idx[n], idx[n+1])
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package lcs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lucckkas/patternfinder/internal/align"
+)
+
+func TestBLOSUM62(t *testing.T) {
+	m := align.BLOSUM62()
+	letters := "ARNDCQEGHILKMFPSTWYVBZX"
+	for i := 0; i < len(letters); i++ {
+		for j := 0; j < len(letters); j++ {
+			if m.Score(letters[i], letters[j]) != m.Score(letters[j], letters[i]) {
+				t.Fatalf("matriz no simétrica en %c/%c", letters[i], letters[j])
+			}
+		}
+	}
+	if got := m.Score('W', 'W'); got != 11 {
+		t.Errorf("W/W=%d, want 11", got)
+	}
+	if got := m.Score('c', 'C'); got != 9 {
+		t.Errorf("c/C=%d, want 9", got)
+	}
+}
+
+func TestParseMatrixErrors(t *testing.T) {
+	for _, bad := range []string{
+		"",
+		"A B\nA 1\n",
+		"A B\nA 1 x\nB 0 1\n",
+		"A B\nA 1 0\n",
+		"A B\nA 1 0\nA 1 0\n",
+	} {
+		if _, err := align.ParseMatrix(strings.NewReader(bad)); err == nil {
+			t.Errorf("ParseMatrix(%q) should fail", bad)
+		}
+	}
+}
+
+func TestAlignGlobal(t *testing.T) {
+	m, err := align.ParseMatrix(strings.NewReader("A B\nA 2 -1\nB -1 2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := align.Options{Matrix: m, GapOpen: 3, GapExtend: 1}
+
+	score, alns := align.Align("AABB", "AABB", opts)
+	if score != 8 || len(alns) != 1 || alns[0].AlignedX != "AABB" {
+		t.Fatalf("identical: score=%d alns=%v", score, alns)
+	}
+
+	// Un gap de largo 2 cuesta 3+1
+	score, alns = align.Align("AABBA", "AAA", opts)
+	if score != 2 {
+		t.Errorf("score=%d, want 2", score)
+	}
+	for _, a := range alns {
+		if strings.ReplaceAll(a.AlignedX, "-", "") != "AABBA" || strings.ReplaceAll(a.AlignedY, "-", "") != "AAA" {
+			t.Errorf("alignment %q/%q does not spell the inputs", a.AlignedX, a.AlignedY)
+		}
+		if a.Score != score {
+			t.Errorf("alignment score=%d, want %d", a.Score, score)
+		}
+	}
+}
+
+func TestAlignCoOptimal(t *testing.T) {
+	m, _ := align.ParseMatrix(strings.NewReader("A B\nA 1 -1\nB -1 1\n"))
+	// "A" puede alinearse con cualquiera de las dos A de "AA"
+	_, alns := align.Align("A", "AA", align.Options{Matrix: m, GapOpen: 1, GapExtend: 1})
+	if len(alns) != 2 {
+		t.Fatalf("got %d co-optimal alignments, want 2", len(alns))
+	}
+	_, alns = align.Align("A", "AA", align.Options{Matrix: m, GapOpen: 1, GapExtend: 1, MaxAlignments: 1})
+	if len(alns) != 1 {
+		t.Errorf("MaxAlignments=1 returned %d alignments", len(alns))
+	}
+}
+
+func TestAlignIdentitiesAreCommonSubsequences(t *testing.T) {
+	opts := align.Options{GapOpen: align.DefaultGapOpen, GapExtend: align.DefaultGapExtend}
+	for _, local := range []bool{false, true} {
+		opts.Local = local
+		for k := 0; k < 20; k++ {
+			x := generateRandomSequence(30, int64(2*k))
+			y := generateRandomSequence(25, int64(2*k+1))
+			_, alns := align.Align(x, y, opts)
+			for _, a := range alns {
+				motif, cols := a.Identities(x, y)
+				if len(cols) != len(motif) {
+					t.Fatalf("columns/motif length mismatch")
+				}
+				if !isSubsequence(motif, x) || !isSubsequence(motif, y) {
+					t.Fatalf("motif %q is not a common subsequence of %q, %q", motif, x, y)
+				}
+			}
+		}
+	}
+}
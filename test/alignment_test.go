@@ -0,0 +1,59 @@
+package lcs_test
+
+import (
+	"testing"
+
+	"github.com/lucckkas/patternfinder/internal/lcs"
+	"github.com/lucckkas/patternfinder/internal/utils"
+)
+
+// checkAlignment verifica que la incrustación sea válida y creciente
+func checkAlignment(t *testing.T, a lcs.Alignment, sec1, sec2 string) {
+	t.Helper()
+	if len(a.Matches) != len(a.LCS) {
+		t.Fatalf("%q: %d matches for %d letters", a.LCS, len(a.Matches), len(a.LCS))
+	}
+	for k, m := range a.Matches {
+		if sec1[m.I] != a.LCS[k] || sec2[m.J] != a.LCS[k] {
+			t.Errorf("%q: match %d (%d,%d) does not spell %c", a.LCS, k, m.I, m.J, a.LCS[k])
+		}
+		if k > 0 && (m.I <= a.Matches[k-1].I || m.J <= a.Matches[k-1].J) {
+			t.Errorf("%q: match %d is not increasing", a.LCS, k)
+		}
+	}
+}
+
+func TestEmbedLCSOriginal(t *testing.T) {
+	seqX := "acBdeFghIjkBlmF" + generateRandomSequence(30, 51)
+	seqY := "BqqFrrIssB" + generateRandomSequence(25, 52)
+	px, py := utils.Project(seqX), utils.Project(seqY)
+	Ux, Uy := px.Upper, py.Upper
+	dp := lcs.DPTable(Ux, Uy)
+
+	for _, s := range lcs.Backtracking(Ux, Uy, dp) {
+		a, ok := lcs.EmbedLCS(s, Ux, Uy)
+		if !ok {
+			t.Fatalf("LCS %q should embed", s)
+		}
+		checkAlignment(t, a, Ux, Uy)
+
+		origX, origY := px.OriginalPositions(a.IndicesX()), py.OriginalPositions(a.IndicesY())
+		for k := range a.Matches {
+			if seqX[origX[k]] != a.LCS[k] || seqY[origY[k]] != a.LCS[k] {
+				t.Errorf("%q: original positions (%d,%d) do not spell %c", a.LCS, origX[k], origY[k], a.LCS[k])
+			}
+		}
+	}
+}
+
+func TestEmbedLCS(t *testing.T) {
+	a, ok := lcs.EmbedLCS("BDAB", "ABCBDAB", "BDCABA")
+	if !ok {
+		t.Fatalf("BDAB should embed")
+	}
+	checkAlignment(t, a, "ABCBDAB", "BDCABA")
+
+	if _, ok := lcs.EmbedLCS("ZZ", "ABCBDAB", "BDCABA"); ok {
+		t.Errorf("ZZ should not embed")
+	}
+}
@@ -190,6 +190,13 @@ func BenchmarkDPTableOnly(b *testing.B) {
 				_ = lcs.DPTableParallel(seq1, seq2)
 			}
 		})
+
+		b.Run(fmt.Sprintf("Tiled_len%d", length), func(b *testing.B) {
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = lcs.DPTableTiled(seq1, seq2, lcs.DefaultTileSize)
+			}
+		})
 	}
 }
 
@@ -0,0 +1,50 @@
+package lcs_test
+
+import (
+	"testing"
+
+	"github.com/lucckkas/patternfinder/internal/lcs"
+)
+
+func TestLengthBitParallelMatchesDPTable(t *testing.T) {
+	tests := []struct {
+		name string
+		sec1 string
+		sec2 string
+	}{
+		{"Simple", "ABC", "AC"},
+		{"Example from docs", "BABCBDABB", "DBDCABA"},
+		{"Equal strings", "ABCD", "ABCD"},
+		{"No common", "ABC", "DEF"},
+		{"Empty sec2", "ABC", ""},
+		{"Empty sec1", "", "ABC"},
+		{"Both empty", "", ""},
+		{"Longer sequences", "AGGTAB", "GXTXAYB"},
+		{"Exactly 64", generateRandomSequence(64, 5), generateRandomSequence(70, 6)},
+		{"Word boundary 65", generateRandomSequence(65, 7), generateRandomSequence(64, 8)},
+		{"Repetitive", "CCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCH", "HCCCCH"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := lcs.DPTable(tt.sec1, tt.sec2)[len(tt.sec1)][len(tt.sec2)]
+			if got := lcs.LengthBitParallel(tt.sec1, tt.sec2); got != want {
+				t.Errorf("LengthBitParallel=%d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestLengthBitParallelRandom(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		n := 1 + int(seed*37%300)
+		m := 1 + int(seed*53%250)
+		sec1 := generateRandomSequence(n, seed)
+		sec2 := generateRandomSequence(m, seed+1000)
+
+		want := lcs.DPTable(sec1, sec2)[n][m]
+		if got := lcs.LengthBitParallel(sec1, sec2); got != want {
+			t.Errorf("seed %d (%dx%d): LengthBitParallel=%d, want %d", seed, n, m, got, want)
+		}
+	}
+}
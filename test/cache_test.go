@@ -0,0 +1,174 @@
+package lcs_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lucckkas/patternfinder/internal/cache"
+	"github.com/lucckkas/patternfinder/internal/compare"
+)
+
+func TestCacheGetPut(t *testing.T) {
+	c, err := cache.Open(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := cache.Key("a", "b")
+	if key == cache.Key("ab", "") {
+		t.Error("Key should separate its parts")
+	}
+	if _, ok := c.Get(key); ok {
+		t.Fatal("unexpected hit on empty cache")
+	}
+	if err := c.Put(key, []byte("hola")); err != nil {
+		t.Fatal(err)
+	}
+	data, ok := c.Get(key)
+	if !ok || string(data) != "hola" {
+		t.Fatalf("Get = %q, %v", data, ok)
+	}
+	if c.Hits() != 1 || c.Misses() != 1 || c.Size() != 4 {
+		t.Errorf("hits=%d misses=%d size=%d", c.Hits(), c.Misses(), c.Size())
+	}
+
+	// Un caché nil no guarda ni acierta
+	var none *cache.Cache
+	if err := none.Put(key, []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := none.Get(key); ok {
+		t.Error("nil cache hit")
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	dir := t.TempDir()
+	c, err := cache.Open(dir, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := bytes.Repeat([]byte("x"), 200)
+	keys := make([]string, 8)
+	old := time.Now().Add(-time.Hour)
+	for i := range keys {
+		keys[i] = cache.Key("entrada", string(rune('a'+i)))
+		if err := c.Put(keys[i], entry); err != nil {
+			t.Fatal(err)
+		}
+		// Fechas de uso crecientes: la entrada 0 es la más vieja
+		mtime := old.Add(time.Duration(i) * time.Minute)
+		os.Chtimes(filepath.Join(dir, keys[i][:2], keys[i]), mtime, mtime)
+		if i == 1 {
+			// Usar la entrada 0 la vuelve la más reciente
+			c.Get(keys[0])
+		}
+	}
+
+	if c.Size() > 1000 {
+		t.Errorf("size %d exceeds limit", c.Size())
+	}
+	if _, ok := c.Get(keys[1]); ok {
+		t.Error("least recently used entry was not evicted")
+	}
+	if _, ok := c.Get(keys[7]); !ok {
+		t.Error("newest entry was evicted")
+	}
+
+	// Las entradas de más de un cuarto del límite no se guardan
+	big := cache.Key("grande")
+	c.Put(big, bytes.Repeat([]byte("x"), 300))
+	if _, ok := c.Get(big); ok {
+		t.Error("oversized entry was stored")
+	}
+
+	// Reabrir recalcula el tamaño desde el disco
+	again, err := cache.Open(dir, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Size() != c.Size() {
+		t.Errorf("reopened size %d, want %d", again.Size(), c.Size())
+	}
+}
+
+func TestCacheEvictionForeignFiles(t *testing.T) {
+	// -cache-dir puede apuntar a un directorio con archivos del usuario
+	dir := t.TempDir()
+	old := time.Now().Add(-24 * time.Hour)
+	foreign := []string{
+		filepath.Join(dir, "resultados.csv"),
+		filepath.Join(dir, "ab", "notas.txt"),
+		filepath.Join(dir, "datos", cache.Key("no es una entrada")),
+	}
+	for _, path := range foreign {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, bytes.Repeat([]byte("u"), 400), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// Más viejos que cualquier entrada: serían los primeros en borrarse
+		os.Chtimes(path, old, old)
+	}
+
+	c, err := cache.Open(dir, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Size() != 0 {
+		t.Errorf("foreign files counted as entries: size %d", c.Size())
+	}
+	entry := bytes.Repeat([]byte("x"), 200)
+	for i := 0; i < 8; i++ {
+		if err := c.Put(cache.Key("entrada", string(rune('a'+i))), entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c.Size() > 1000 {
+		t.Errorf("size %d exceeds limit", c.Size())
+	}
+	for _, path := range foreign {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("eviction removed a file that is not an entry: %v", err)
+		}
+	}
+}
+
+func TestComparePairCached(t *testing.T) {
+	c, err := cache.Open(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := compare.DefaultOptions()
+	first, hit := compare.PairCached(context.Background(), c, "AxxBxxxC", "AyyyyBzzzC", opts)
+	if hit {
+		t.Fatal("unexpected hit")
+	}
+	second, hit := compare.PairCached(context.Background(), c, "AxxBxxxC", "AyyyyBzzzC", opts)
+	if !hit || second.Text != first.Text || len(second.Report.Patterns) != 1 {
+		t.Fatalf("second call: hit=%v, text equal=%v", hit, second.Text == first.Text)
+	}
+
+	var direct bytes.Buffer
+	compare.Pair(context.Background(), "AxxBxxxC", "AyyyyBzzzC", opts).WriteText(&direct)
+	if second.Text != direct.String() {
+		t.Errorf("cached text differs from Pair:\n%s\nvs\n%s", second.Text, direct.String())
+	}
+
+	// Otras opciones, otra entrada
+	opts.MaxComb = 1
+	if _, hit := compare.PairCached(context.Background(), c, "AxxBxxxC", "AyyyyBzzzC", opts); hit {
+		t.Error("different options should not hit")
+	}
+
+	// Los resultados cortados por tiempo no se guardan
+	opts.Timeout = time.Nanosecond
+	compare.PairCached(context.Background(), c, "ABCABCABC", "CBACBACBA", opts)
+	if _, hit := compare.PairCached(context.Background(), c, "ABCABCABC", "CBACBACBA", opts); hit {
+		t.Error("timed-out result was cached")
+	}
+}
@@ -0,0 +1,78 @@
+package lcs_test
+
+import (
+	"testing"
+
+	"github.com/lucckkas/patternfinder/internal/aggregate"
+	"github.com/lucckkas/patternfinder/internal/gaps"
+	"github.com/lucckkas/patternfinder/internal/lcs"
+)
+
+func TestParseClasses(t *testing.T) {
+	c, err := lcs.ParseClasses("[CH][DE][KR][ILVM]")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := c.Translate("aHbEcRdMe"); got != "aCbDcKdIe" {
+		t.Errorf("Translate=%q, want aCbDcKdIe", got)
+	}
+	if got := c.Render("CWD"); got != "[CH]W[DE]" {
+		t.Errorf("Render=%q, want [CH]W[DE]", got)
+	}
+
+	preset, err := lcs.ParseClasses("metal")
+	if err != nil || preset.String() != "[CH][DE]" {
+		t.Errorf("preset metal=%v (err %v), want [CH][DE]", preset, err)
+	}
+
+	for _, bad := range []string{"[CH", "CH", "[]", "[Ch]", "[CH][HD]"} {
+		if _, err := lcs.ParseClasses(bad); err == nil {
+			t.Errorf("ParseClasses(%q) should fail", bad)
+		}
+	}
+
+	// Sin clases: identidad
+	var none *lcs.Classes
+	if none.Translate("aHb") != "aHb" || none.Render("CH") != "CH" {
+		t.Errorf("nil classes must not change sequences")
+	}
+}
+
+func TestClassesLCS(t *testing.T) {
+	c, _ := lcs.ParseClasses("metal")
+	seqX := c.Translate("aCbbHdE")
+	seqY := c.Translate("HxxxCD")
+
+	// Solo mayúsculas para el LCS, secuencias completas para los gaps
+	Ux, Uy := c.Translate("CHE"), c.Translate("HCD")
+	all := lcs.Backtracking(Ux, Uy, lcs.DPTable(Ux, Uy))
+	if len(all) != 1 || all[0] != "CCD" {
+		t.Fatalf("LCS by class=%v, want [CCD]", all)
+	}
+
+	pat := all[0]
+	setsX, okX := gaps.AllGapValuesDistanceTotalViable(seqX, pat)
+	setsY, okY := gaps.AllGapValuesDistanceTotalViable(seqY, pat)
+	if !okX || !okY {
+		t.Fatalf("gaps not found for %q", pat)
+	}
+	union := aggregate.PairUnionSets(setsX, setsY)
+	if got := aggregate.FormatPatternWithSymbols(pat, union, c.Symbol); len(got) != 1 || got[0] != "[CH]-x(2,3)-[CH]-x(0,1)-[DE]" {
+		t.Errorf("FormatPatternWithSymbols=%q", got)
+	}
+}
+
+func TestConsolidatePatternsWithClasses(t *testing.T) {
+	stats := map[string]*gaps.PatternStat{
+		"[CH]-x(2)-C": {Pattern: "[CH]-x(2)-C", SequenceIndices: map[int]bool{1: true, 2: true}},
+		"[CH]-x(3)-C": {Pattern: "[CH]-x(3)-C", SequenceIndices: map[int]bool{3: true}},
+	}
+	out := gaps.ConsolidatePatterns(stats)
+	stat, ok := out["[CH]-x(2,3)-C"]
+	if !ok || len(out) != 1 {
+		t.Fatalf("consolidated=%v, want [CH]-x(2,3)-C", out)
+	}
+	if stat.UppercaseCount != 2 || len(stat.SequenceIndices) != 3 {
+		t.Errorf("UppercaseCount=%d sequences=%d, want 2 and 3", stat.UppercaseCount, len(stat.SequenceIndices))
+	}
+}
@@ -0,0 +1,211 @@
+package lcs_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucckkas/patternfinder/internal/compare"
+	"github.com/lucckkas/patternfinder/internal/lcs"
+)
+
+func TestComparePair(t *testing.T) {
+	opts := compare.DefaultOptions()
+	r := compare.Pair(context.Background(), "AxxBxxxC", "AyyyyBzzzC", opts)
+	if len(r.Patterns) != 1 {
+		t.Fatalf("got %d patterns, want 1", len(r.Patterns))
+	}
+	p := r.Patterns[0]
+	if p.Base != "ABC" || !p.GapsOK {
+		t.Fatalf("pattern=%+v", p)
+	}
+	want := []string{"A-x(2)-B-x(3)-C", "A-x(4)-B-x(3)-C"}
+	if strings.Join(p.Combinations, " ") != strings.Join(want, " ") {
+		t.Errorf("combinations=%v, want %v", p.Combinations, want)
+	}
+	if p.ResiduesX != "A1-x(2)-B4-x(3)-C8" {
+		t.Errorf("ResiduesX=%q", p.ResiduesX)
+	}
+
+	var buf bytes.Buffer
+	r.WriteText(&buf)
+	for _, line := range []string{"1 LCS encontradas", "[1] Patrón base: ABC", "    [1.2] A-x(4)-B-x(3)-C"} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("text output missing %q:\n%s", line, buf.String())
+		}
+	}
+}
+
+func TestComparePairLimits(t *testing.T) {
+	opts := compare.DefaultOptions()
+	opts.MaxLCS = 2
+	r := compare.Pair(context.Background(), "ABCABCABC", "CBACBACBA", opts)
+	if !r.Rejected || len(r.Patterns) != 0 {
+		t.Errorf("expected rejection, got %d patterns (total %v)", len(r.Patterns), r.Total)
+	}
+
+	opts.Sample = true
+	r = compare.Pair(context.Background(), "ABCABCABC", "CBACBACBA", opts)
+	if !r.Sampled || len(r.Patterns) != 2 {
+		t.Errorf("expected 2 sampled patterns, got %d", len(r.Patterns))
+	}
+
+	opts = compare.DefaultOptions()
+	opts.Limits = lcs.Limits{MaxCount: 1}
+	r = compare.Pair(context.Background(), "ABCABCABC", "CBACBACBA", opts)
+	if !r.Truncated || len(r.Patterns) != 1 {
+		t.Errorf("expected truncation at 1 pattern, got %d (truncated %v)", len(r.Patterns), r.Truncated)
+	}
+
+	// Con -limit o -timeout un par con más LCS que -max-lcs no se rechaza:
+	// se devuelven las primeras (nunca más de -max-lcs)
+	opts.MaxLCS = 2
+	r = compare.Pair(context.Background(), "ABCABCABC", "CBACBACBA", opts)
+	if r.Rejected || !r.Truncated || len(r.Patterns) != 1 || r.Status() != compare.StatusOK {
+		t.Errorf("-limit 1 -max-lcs 2: rejected %v, truncated %v, %d patterns", r.Rejected, r.Truncated, len(r.Patterns))
+	}
+	opts.Limits = lcs.Limits{}
+	opts.Timeout = time.Hour
+	r = compare.Pair(context.Background(), "ABCABCABC", "CBACBACBA", opts)
+	if r.Rejected || !r.Truncated || len(r.Patterns) != 2 || !errors.Is(r.StopReason, lcs.ErrMaxCount) {
+		t.Errorf("-timeout -max-lcs 2: rejected %v, truncated %v, %d patterns (%v)", r.Rejected, r.Truncated, len(r.Patterns), r.StopReason)
+	}
+
+	if r := compare.Pair(context.Background(), "abc", "ABC", compare.DefaultOptions()); !r.NoUppercase {
+		t.Errorf("expected NoUppercase")
+	}
+}
+
+func TestComparePairGuards(t *testing.T) {
+	base := compare.Pair(context.Background(), "ABCABCABC", "CBACBACBA", compare.DefaultOptions())
+
+	// Con un plazo holgado la enumeración es incremental pero el resultado es el mismo
+	opts := compare.DefaultOptions()
+	opts.Timeout = time.Hour
+	r := compare.Pair(context.Background(), "ABCABCABC", "CBACBACBA", opts)
+	if r.TimedOut || len(r.Patterns) != len(base.Patterns) || r.Status() != compare.StatusOK {
+		t.Fatalf("timeout 1h: %d patterns (want %d), status %s", len(r.Patterns), len(base.Patterns), r.Status())
+	}
+	for i := range r.Patterns {
+		if r.Patterns[i].Base != base.Patterns[i].Base {
+			t.Errorf("pattern %d = %s, want %s", i, r.Patterns[i].Base, base.Patterns[i].Base)
+		}
+	}
+
+	// Un plazo vencido deja resultados parciales con estado timeout
+	opts.Timeout = time.Nanosecond
+	r = compare.Pair(context.Background(), "ABCABCABC", "CBACBACBA", opts)
+	if !r.TimedOut || r.Status() != compare.StatusTimeout {
+		t.Fatalf("expected timeout, got status %s", r.Status())
+	}
+	if len(r.Patterns)+r.Dropped != len(base.Patterns) {
+		t.Errorf("patterns %d + dropped %d != %d", len(r.Patterns), r.Dropped, len(base.Patterns))
+	}
+	var buf bytes.Buffer
+	r.WriteText(&buf)
+	if !strings.Contains(buf.String(), "[TIEMPO AGOTADO]") {
+		t.Errorf("text output missing timeout notice:\n%s", buf.String())
+	}
+
+	// Una cancelación de afuera corta la comparación sin marcarla como timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r = compare.Pair(ctx, "ABCABCABC", "CBACBACBA", compare.DefaultOptions())
+	if !r.Truncated || !errors.Is(r.StopReason, context.Canceled) || r.TimedOut || len(r.Patterns) != 0 {
+		t.Errorf("canceled: truncated %v, reason %v, timed out %v, %d patterns", r.Truncated, r.StopReason, r.TimedOut, len(r.Patterns))
+	}
+	if r.Status() != compare.StatusCanceled || r.Report().Status != compare.StatusCanceled {
+		t.Errorf("canceled: status %s, want %s", r.Status(), compare.StatusCanceled)
+	}
+
+	// Solo un resultado completo (o con el tope de -limit) queda como ok
+	for reason, want := range map[error]string{
+		lcs.ErrMaxTime:           compare.StatusTimeout, // -timeout de patternfinder
+		context.DeadlineExceeded: compare.StatusTimeout,
+		context.Canceled:         compare.StatusCanceled,
+		lcs.ErrMaxCount:          compare.StatusOK,
+	} {
+		r := compare.Result{Truncated: true, StopReason: reason}
+		if got := r.Status(); got != want {
+			t.Errorf("stop reason %v: status %s, want %s", reason, got, want)
+		}
+	}
+
+	// -max-comb: se informa la cantidad sin generar las combinaciones
+	opts = compare.DefaultOptions()
+	opts.MaxComb = 1
+	r = compare.Pair(context.Background(), "AxxBxxxC", "AyyyyBzzzC", opts)
+	p := r.Patterns[0]
+	if p.Omitted == nil || p.Omitted.Int64() != 2 || len(p.Combinations) != 0 {
+		t.Errorf("expected 2 omitted combinations, got %v (%v)", p.Omitted, p.Combinations)
+	}
+	if rep := r.Report(); rep.Patterns[0].Omitted != "2" {
+		t.Errorf("report combinations_omitted = %q", rep.Patterns[0].Omitted)
+	}
+}
+
+func TestComparePairAlign(t *testing.T) {
+	opts := compare.DefaultOptions()
+	opts.Mode = compare.ModeAlign
+	r := compare.Pair(context.Background(), "aCbbHdEkW", "HxxxCDqqW", opts)
+	if len(r.Alignments) == 0 || len(r.Patterns) == 0 {
+		t.Fatalf("expected alignments and motifs, got %+v", r)
+	}
+	for _, p := range r.Patterns {
+		if !isSubsequence(p.Base, r.Upper1) || !isSubsequence(p.Base, r.Upper2) {
+			t.Errorf("motif %q is not a common subsequence", p.Base)
+		}
+	}
+
+	// Tres tablas de 5×5 celdas de 8 bytes: 600 bytes
+	opts.MemBudget = 599
+	r = compare.Pair(context.Background(), "aCbbHdEkW", "HxxxCDqqW", opts)
+	if !r.OverBudget || len(r.Alignments) != 0 || r.Status() != compare.StatusRejected {
+		t.Errorf("budget 599: OverBudget %v, %d alignments, status %s", r.OverBudget, len(r.Alignments), r.Status())
+	}
+	opts.MemBudget = 600
+	if r = compare.Pair(context.Background(), "aCbbHdEkW", "HxxxCDqqW", opts); r.OverBudget || len(r.Alignments) == 0 {
+		t.Errorf("budget 600 should fit the alignment tables")
+	}
+}
+
+func TestCompareJSON(t *testing.T) {
+	r := compare.Pair(context.Background(), "AxxBxxxC", "AyyyyBzzzC", compare.DefaultOptions())
+	var buf bytes.Buffer
+	if err := r.WriteJSON(&buf, true); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("jsonl output should be a single line: %q", buf.String())
+	}
+
+	var rep compare.Report
+	if err := json.Unmarshal(buf.Bytes(), &rep); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if rep.Schema != compare.SchemaVersion || rep.Status != compare.StatusOK || rep.TotalLCS != "1" {
+		t.Errorf("unexpected header: %+v", rep)
+	}
+	if len(rep.Patterns) != 1 || rep.Patterns[0].LCS != "ABC" {
+		t.Fatalf("patterns=%+v", rep.Patterns)
+	}
+	if got := rep.Patterns[0].Gaps; len(got) != 2 || len(got[0]) != 2 || got[1][0] != 3 {
+		t.Errorf("gaps=%v, want [[2 4] [3]]", got)
+	}
+
+	// Residuos contiguos en ambas secuencias: gap [0], no una lista vacía
+	rep = compare.Pair(context.Background(), "ABxC", "ABC", compare.DefaultOptions()).Report()
+	if got := rep.Patterns[0].Gaps; !reflect.DeepEqual(got, [][]int{{0}, {0, 1}}) {
+		t.Errorf("gaps=%v, want [[0] [0 1]]", got)
+	}
+
+	r = compare.Pair(context.Background(), "abc", "ABC", compare.DefaultOptions())
+	if rep := r.Report(); rep.Status != compare.StatusNoUppercase || rep.Patterns == nil {
+		t.Errorf("no-uppercase report=%+v", rep)
+	}
+}
@@ -0,0 +1,78 @@
+package lcs_test
+
+import (
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/lucckkas/patternfinder/internal/lcs"
+)
+
+func TestCountDistinctMatchesBacktracking(t *testing.T) {
+	tests := []struct {
+		name string
+		sec1 string
+		sec2 string
+	}{
+		{"Simple", "ABC", "AC"},
+		{"Example from docs", "BABCBDABB", "DBDCABA"},
+		{"No common", "ABC", "DEF"},
+		{"Reversed", "ABCDEFGH", "HGFEDCBA"},
+		{"Repetitive", "ABABABABAB", "BABABABA"},
+		{"Random 40x35", generateRandomSequence(40, 11), generateRandomSequence(35, 12)},
+		{"Random 60x60", generateRandomSequence(60, 13), generateRandomSequence(60, 14)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp := lcs.DPTable(tt.sec1, tt.sec2)
+			want := len(lcs.Backtracking(tt.sec1, tt.sec2, dp))
+			got := lcs.CountDistinct(tt.sec1, tt.sec2, dp)
+			if !got.IsInt64() || got.Int64() != int64(want) {
+				t.Errorf("CountDistinct=%s, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestCountDistinctLarge(t *testing.T) {
+	// Con k bloques "xy"/"yx" de letras distintas hay 2^k LCS distintas:
+	// el conteo debe resolverse sin enumerar y sin desbordar int64.
+	const blocks = 100
+	var b1, b2 strings.Builder
+	for k := 0; k < blocks; k++ {
+		x, y := byte(2*k+1), byte(2*k+2)
+		b1.Write([]byte{x, y})
+		b2.Write([]byte{y, x})
+	}
+	sec1, sec2 := b1.String(), b2.String()
+	dp := lcs.DPTable(sec1, sec2)
+
+	want := new(big.Int).Lsh(big.NewInt(1), blocks)
+	if got := lcs.CountDistinct(sec1, sec2, dp); got.Cmp(want) != 0 {
+		t.Errorf("CountDistinct=%s, want 2^%d", got, blocks)
+	}
+}
+
+func TestCounterSampleIsLCS(t *testing.T) {
+	sec1 := generateRandomSequence(80, 21)
+	sec2 := generateRandomSequence(70, 22)
+	dp := lcs.DPTable(sec1, sec2)
+
+	valid := make(map[string]bool)
+	for _, s := range lcs.Backtracking(sec1, sec2, dp) {
+		valid[s] = true
+	}
+
+	c := lcs.NewCounter(sec1, sec2, dp)
+	samples := c.SampleDistinct(5, rand.New(rand.NewSource(1)))
+	if want := min(5, len(valid)); len(samples) != want {
+		t.Fatalf("got %d samples, want %d", len(samples), want)
+	}
+	for _, s := range samples {
+		if !valid[s] {
+			t.Errorf("sample %q is not an LCS", s)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package lcs_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lucckkas/patternfinder/internal/enrich"
+)
+
+func TestFisherGreater(t *testing.T) {
+	cases := []struct {
+		a, b, c, d int
+		want       float64
+	}{
+		{3, 0, 0, 3, 0.05},           // 1/C(6,3)
+		{0, 3, 3, 0, 1},              // ningún positivo: nada es más extremo
+		{2, 1, 1, 2, 0.5},            // (9+1)/20
+		{1, 9, 11, 3, 0.99996596},    // más frecuente en los negativos
+		{10, 0, 0, 10, 1.0 / 184756}, // 1/C(20,10)
+	}
+	for _, c := range cases {
+		if got := enrich.FisherGreater(c.a, c.b, c.c, c.d); math.Abs(got-c.want) > 1e-6 {
+			t.Errorf("FisherGreater(%d,%d,%d,%d)=%g, want %g", c.a, c.b, c.c, c.d, got, c.want)
+		}
+	}
+}
+
+func TestNewContrast(t *testing.T) {
+	c := enrich.NewContrast("C-x(2)-C", 8, 10, 2, 10)
+	if math.Abs(c.SupportDiff-0.6) > 1e-12 || c.OddsRatio != 16 {
+		t.Errorf("NewContrast=%+v, want diff 0.6 and OR 16", c)
+	}
+	// Con una celda en 0 se aplica la corrección de Haldane
+	c = enrich.NewContrast("C-x(2)-C", 10, 10, 0, 10)
+	if want := 10.5 * 10.5 / (0.5 * 0.5); c.OddsRatio != want || math.IsInf(c.OddsRatio, 0) {
+		t.Errorf("OddsRatio=%g, want %g", c.OddsRatio, want)
+	}
+}
+
+func TestDiscriminate(t *testing.T) {
+	pos := []string{"aCbbCdH", "CxxCHk", "kkCeeCa", "HdCyyCH"}
+	neg := []string{"aCbbHdE", "kkHeeWa", "CxxCkk", "WWWWW"}
+	ranking, err := enrich.Discriminate(pos, neg, []string{"H", "C-x(2)-C", "W"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for i, c := range ranking {
+		if c.Rank != i+1 {
+			t.Errorf("%s: Rank=%d, want %d", c.Pattern, c.Rank, i+1)
+		}
+		order = append(order, c.Pattern)
+	}
+	if order[0] != "C-x(2)-C" || order[2] != "W" {
+		t.Errorf("ranking=%v, want C-x(2)-C first and W last", order)
+	}
+	if c := ranking[0]; c.Pos != 4 || c.Neg != 1 || c.PosTotal != 4 || c.NegTotal != 4 {
+		t.Errorf("support %+v, want 4/4 vs 1/4", c)
+	}
+
+	if _, err := enrich.Discriminate(pos, neg, []string{"C-x(2"}, ""); err == nil {
+		t.Errorf("invalid patterns must fail")
+	}
+}
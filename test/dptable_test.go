@@ -103,3 +103,39 @@ func TestDPTableParallelWithBacktracking(t *testing.T) {
 
 	t.Logf("Found %d LCS strings correctly", len(setSeq))
 }
+
+func TestDPTableTiledCorrectness(t *testing.T) {
+	tests := []struct {
+		name string
+		sec1 string
+		sec2 string
+		tile int
+	}{
+		{"Simple", "ABC", "AC", 0},
+		{"Example from docs", "BABCBDABB", "DBDCABA", 2},
+		{"Empty sec1", "", "ABC", 4},
+		{"Tile larger than table", "AGGTAB", "GXTXAYB", 100},
+		{"Ragged tiles", generateRandomSequence(131, 61), generateRandomSequence(97, 62), 16},
+		{"Tile size 1", generateRandomSequence(40, 63), generateRandomSequence(45, 64), 1},
+		{"Default tile", generateRandomSequence(300, 65), generateRandomSequence(257, 66), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dpSeq := lcs.DPTable(tt.sec1, tt.sec2)
+			dpTiled := lcs.DPTableTiled(tt.sec1, tt.sec2, tt.tile)
+
+			if len(dpSeq) != len(dpTiled) {
+				t.Fatalf("Different number of rows: seq=%d, tiled=%d", len(dpSeq), len(dpTiled))
+			}
+			for i := range dpSeq {
+				for j := range dpSeq[i] {
+					if dpSeq[i][j] != dpTiled[i][j] {
+						t.Fatalf("Mismatch at dp[%d][%d]: seq=%d, tiled=%d",
+							i, j, dpSeq[i][j], dpTiled[i][j])
+					}
+				}
+			}
+		})
+	}
+}
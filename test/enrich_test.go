@@ -0,0 +1,97 @@
+package lcs_test
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/lucckkas/patternfinder/internal/enrich"
+)
+
+func TestBenjaminiHochberg(t *testing.T) {
+	got := enrich.BenjaminiHochberg([]float64{0.01, 0.04, 0.03, 0.5})
+	want := []float64{0.04, 0.16 / 3, 0.16 / 3, 0.5}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-12 {
+			t.Fatalf("BenjaminiHochberg=%v, want %v", got, want)
+		}
+	}
+	if q := enrich.BenjaminiHochberg([]float64{0.9, 0.95}); q[0] != 0.95 || q[1] != 0.95 {
+		t.Errorf("BenjaminiHochberg must be monotone and capped: %v", q)
+	}
+}
+
+func sortedBytes(s string) string {
+	b := []byte(s)
+	sort.Slice(b, func(i, j int) bool { return b[i] < b[j] })
+	return string(b)
+}
+
+func TestNullModels(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	s := "aCbbHdEkWxxC"
+	if sh := enrich.Shuffle(s, rng); sortedBytes(sh) != sortedBytes(s) {
+		t.Errorf("Shuffle changed the composition: %q", sh)
+	}
+
+	// Solo transiciones A→B y B→A: la cadena tiene que alternar
+	m := enrich.TrainMarkov([]string{"ABABAB", "ABAB"})
+	g := m.Generate(9, rand.New(rand.NewSource(1)))
+	if g != "ABABABABA" {
+		t.Errorf("Markov.Generate=%q, want ABABABABA", g)
+	}
+	a := enrich.TrainMarkov([]string{s}).Generate(50, rand.New(rand.NewSource(5)))
+	b := enrich.TrainMarkov([]string{s}).Generate(50, rand.New(rand.NewSource(5)))
+	if a != b || len(a) != 50 {
+		t.Errorf("Markov.Generate must be reproducible: %q vs %q", a, b)
+	}
+}
+
+func TestEnrichAnalyze(t *testing.T) {
+	// Motivo C-x(2)-C plantado en todas las secuencias, con relleno al azar
+	rng := rand.New(rand.NewSource(11))
+	filler := func(n int) string {
+		var b strings.Builder
+		for i := 0; i < n; i++ {
+			b.WriteByte("CHDEWKRacdehkw"[rng.Intn(14)])
+		}
+		return b.String()
+	}
+	var seqs []string
+	for i := 0; i < 20; i++ {
+		seqs = append(seqs, filler(10)+"CaaC"+filler(10))
+	}
+	patterns := []string{"C-x(2)-C", "Q-x(1)-Q"}
+
+	for _, null := range []string{enrich.NullShuffle, enrich.NullMarkov} {
+		opts := enrich.Options{Null: null, Permutations: 99, Seed: 42}
+		res, err := enrich.Analyze(context.Background(), seqs, patterns, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res[0].Observed != 20 || res[0].Expected >= 20 || res[0].PValue > 0.05 {
+			t.Errorf("%s: planted motif %+v should be significant", null, res[0])
+		}
+		if res[1].Observed != 0 || res[1].PValue != 1 || res[1].QValue != 1 {
+			t.Errorf("%s: absent motif %+v should have p = q = 1", null, res[1])
+		}
+
+		// Misma semilla, mismos resultados, con cualquier cantidad de workers
+		opts.Workers = 4
+		again, err := enrich.Analyze(context.Background(), seqs, patterns, opts)
+		if err != nil || !reflect.DeepEqual(res, again) {
+			t.Errorf("%s: results depend on workers: %+v vs %+v (err %v)", null, res, again, err)
+		}
+	}
+
+	if _, err := enrich.Analyze(context.Background(), seqs, []string{"C-x(2"}, enrich.Options{Permutations: 1}); err == nil {
+		t.Errorf("invalid patterns must fail")
+	}
+	if _, err := enrich.Analyze(context.Background(), seqs, patterns, enrich.Options{Null: "uniform", Permutations: 1}); err == nil {
+		t.Errorf("unknown null models must fail")
+	}
+}
@@ -0,0 +1,67 @@
+package lcs_test
+
+import (
+	"testing"
+
+	"github.com/lucckkas/patternfinder/internal/lcs"
+)
+
+// isSubsequence indica si sub es subsecuencia de s
+func isSubsequence(sub, s string) bool {
+	k := 0
+	for i := 0; i < len(s) && k < len(sub); i++ {
+		if s[i] == sub[k] {
+			k++
+		}
+	}
+	return k == len(sub)
+}
+
+func TestHirschbergMatchesDPTable(t *testing.T) {
+	tests := []struct {
+		name string
+		sec1 string
+		sec2 string
+	}{
+		{"Simple", "ABC", "AC"},
+		{"Example from docs", "BABCBDABB", "DBDCABA"},
+		{"Equal strings", "ABCD", "ABCD"},
+		{"No common", "ABC", "DEF"},
+		{"Empty sec2", "ABC", ""},
+		{"Empty sec1", "", "ABC"},
+		{"Both empty", "", ""},
+		{"Longer sequences", "AGGTAB", "GXTXAYB"},
+		{"Random 150x90", generateRandomSequence(150, 1), generateRandomSequence(90, 2)},
+		{"Random 301x300", generateRandomSequence(301, 3), generateRandomSequence(300, 4)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp := lcs.DPTable(tt.sec1, tt.sec2)
+			want := dp[len(tt.sec1)][len(tt.sec2)]
+
+			n, s := lcs.Hirschberg(tt.sec1, tt.sec2)
+			if n != want || len(s) != want {
+				t.Fatalf("Hirschberg length=%d (|s|=%d), want %d", n, len(s), want)
+			}
+			if !isSubsequence(s, tt.sec1) || !isSubsequence(s, tt.sec2) {
+				t.Errorf("%q is not a common subsequence", s)
+			}
+			if got := lcs.LengthLinear(tt.sec1, tt.sec2); got != want {
+				t.Errorf("LengthLinear=%d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestFitsInBudget(t *testing.T) {
+	if !lcs.FitsInBudget(1000, 1000, 0) {
+		t.Errorf("a zero budget must mean no limit")
+	}
+	if lcs.FitsInBudget(1000, 1000, lcs.TableBytes(1000, 1000)-1) {
+		t.Errorf("table should not fit in a budget one byte too small")
+	}
+	if !lcs.FitsInBudget(1000, 1000, lcs.TableBytes(1000, 1000)) {
+		t.Errorf("table should fit in an exact budget")
+	}
+}
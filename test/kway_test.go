@@ -0,0 +1,85 @@
+package lcs_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lucckkas/patternfinder/internal/aggregate"
+	"github.com/lucckkas/patternfinder/internal/lcs"
+)
+
+func TestCommonSubsequenceTwoWay(t *testing.T) {
+	for k := 0; k < 20; k++ {
+		x := generateRandomSequence(20, int64(k))
+		y := generateRandomSequence(15, int64(k+100))
+		got, exact := lcs.CommonSubsequence([]string{x, y}, 0)
+		if !exact {
+			t.Fatalf("expected exact result for small input")
+		}
+		if want := lcs.LengthBitParallel(x, y); len(got) != want {
+			t.Errorf("len=%d, want %d (%q vs %q)", len(got), want, x, y)
+		}
+		if !isSubsequence(got, x) || !isSubsequence(got, y) {
+			t.Errorf("%q is not a common subsequence", got)
+		}
+	}
+}
+
+func TestCommonSubsequenceKWay(t *testing.T) {
+	seqs := []string{"CHXDEW", "ACHDYW", "CZHDWE"}
+	got, exact := lcs.CommonSubsequence(seqs, 0)
+	if !exact || got != "CHDW" {
+		t.Errorf("got %q (exact %v), want CHDW", got, exact)
+	}
+
+	for k := 0; k < 10; k++ {
+		seqs := []string{
+			generateRandomSequence(12, int64(3*k)),
+			generateRandomSequence(10, int64(3*k+1)),
+			generateRandomSequence(11, int64(3*k+2)),
+		}
+		exactLCS, _ := lcs.CommonSubsequence(seqs, 0)
+		heuristic, isExact := lcs.CommonSubsequence(seqs, 1)
+		if isExact {
+			t.Fatalf("expected heuristic with tiny cell budget")
+		}
+		if len(heuristic) > len(exactLCS) {
+			t.Errorf("heuristic %q longer than exact %q", heuristic, exactLCS)
+		}
+		for _, s := range seqs {
+			if !isSubsequence(exactLCS, s) || !isSubsequence(heuristic, s) {
+				t.Fatalf("result not common to %q", s)
+			}
+		}
+	}
+}
+
+func TestConsensus(t *testing.T) {
+	seqs := []string{"CHDW", "ACHDW", "CHXDW", "KKKK"}
+
+	if got := lcs.Consensus(seqs, 1, 0, 0); len(got) != 0 {
+		t.Errorf("min fraction 1: got %v, want none", got)
+	}
+
+	got := lcs.Consensus(seqs, 0.75, 0, 0)
+	if len(got) == 0 || got[0].Pattern != "CHDW" {
+		t.Fatalf("got %v, want CHDW first", got)
+	}
+	if !reflect.DeepEqual(got[0].Members, []int{0, 1, 2}) {
+		t.Errorf("members=%v, want [0 1 2]", got[0].Members)
+	}
+	if top := lcs.Consensus(seqs, 0.5, 1, 0); len(top) != 1 {
+		t.Errorf("top=1 returned %d motifs", len(top))
+	}
+}
+
+func TestUnionSets(t *testing.T) {
+	a := []map[int]struct{}{{1: {}}, {0: {}}}
+	b := []map[int]struct{}{{3: {}}, {0: {}}}
+	c := []map[int]struct{}{{2: {}}, {0: {}}}
+	got := aggregate.UnionSets(a, b, c)
+	want := []aggregate.GapValues{{Values: []int{1, 2, 3}}, {Values: nil}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
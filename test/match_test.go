@@ -0,0 +1,130 @@
+package lcs_test
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/lucckkas/patternfinder/internal/compare"
+	"github.com/lucckkas/patternfinder/internal/gaps"
+	"github.com/lucckkas/patternfinder/internal/scan"
+)
+
+func setValues(s map[int]struct{}) []int {
+	out := make([]int, 0, len(s))
+	for v := range s {
+		out = append(out, v)
+	}
+	sort.Ints(out)
+	return out
+}
+
+func TestAllGapValuesMatch(t *testing.T) {
+	// C0 a1 H2 b3 C4 H5 c6 H7
+	const seq = "CaHbCHcH"
+	cases := []struct {
+		mode string
+		seq  string
+		want []int
+		ok   bool
+	}{
+		{gaps.MatchDefault, seq, []int{0, 1, 2, 4, 6}, true},
+		{gaps.MatchStrict, seq, []int{0, 1}, true},
+		{gaps.MatchInteraction, seq, []int{0, 1, 2, 3}, true},
+		{gaps.MatchRelaxed, seq, []int{0, 1, 2, 4, 6}, true},
+		{gaps.MatchDefault, "cabH", nil, false},
+		{gaps.MatchRelaxed, "cabH", []int{2}, true},
+		{gaps.MatchStrict, "CaDaH", nil, false},
+	}
+	for _, c := range cases {
+		sets, ok := gaps.AllGapValues(c.seq, "CH", c.mode)
+		if ok != c.ok {
+			t.Errorf("%s %s: ok=%v, want %v", c.mode, c.seq, ok, c.ok)
+			continue
+		}
+		if ok && !reflect.DeepEqual(setValues(sets[0]), c.want) {
+			t.Errorf("%s %s: gaps=%v, want %v", c.mode, c.seq, setValues(sets[0]), c.want)
+		}
+	}
+
+	if err := gaps.CheckMatch("loose"); err == nil {
+		t.Errorf("CheckMatch should reject unknown modes")
+	}
+}
+
+func TestScanMatchSemantics(t *testing.T) {
+	const seq = "CaHbCHcH"
+	type hit struct{ start, end, gap int }
+	cases := []struct {
+		mode string
+		want []hit
+	}{
+		{gaps.MatchDefault, []hit{{0, 3, 1}, {0, 6, 4}, {0, 8, 6}, {4, 6, 0}, {4, 8, 2}}},
+		{gaps.MatchStrict, []hit{{0, 3, 1}, {4, 6, 0}}},
+		{gaps.MatchRelaxed, []hit{{0, 3, 1}, {0, 6, 4}, {0, 8, 6}, {4, 6, 0}, {4, 8, 2}, {6, 8, 0}}},
+		// Los gaps cuentan mayúsculas; las posiciones son de la secuencia original
+		{gaps.MatchInteraction, []hit{{0, 3, 0}, {0, 6, 2}, {0, 8, 3}, {4, 6, 0}, {4, 8, 1}}},
+	}
+	for _, c := range cases {
+		m, err := scan.CompileMatch("C-x(0,10)-H", c.mode)
+		if err != nil {
+			t.Fatalf("CompileMatch(%s): %v", c.mode, err)
+		}
+		var got []hit
+		matches, _ := m.FindAll(seq, 0)
+		for _, mt := range matches {
+			got = append(got, hit{mt.Start, mt.End, mt.Gaps[0]})
+			if mt.Residues != seq[mt.Start:mt.End] {
+				t.Errorf("%s: Residues=%q at %d:%d", c.mode, mt.Residues, mt.Start, mt.End)
+			}
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: matches=%v, want %v", c.mode, got, c.want)
+		}
+
+		// Los gaps del scanner y del motor de gaps coinciden en cada semántica
+		sets, _ := gaps.AllGapValues(seq, "CH", c.mode)
+		seen := make(map[int]struct{})
+		for _, h := range got {
+			seen[h.gap] = struct{}{}
+		}
+		if !reflect.DeepEqual(setValues(seen), setValues(sets[0])) {
+			t.Errorf("%s: scanner gaps %v, gap engine %v", c.mode, setValues(seen), setValues(sets[0]))
+		}
+	}
+
+	// {P} en modo relaxed excluye también la minúscula
+	m, _ := scan.CompileMatch("C-{P}", gaps.MatchRelaxed)
+	if m.Matches("Cp") || !m.Matches("cQ") || !m.Matches("Cq") {
+		t.Errorf("relaxed {P} matched wrongly")
+	}
+	if _, err := scan.CompileMatch("C", "loose"); err == nil {
+		t.Errorf("CompileMatch should reject unknown modes")
+	}
+}
+
+func TestComparePairMatch(t *testing.T) {
+	opts := compare.DefaultOptions()
+	opts.Match = gaps.MatchInteraction
+	r := compare.Pair(context.Background(), "aCbbHdEkW", "HxxxCDqqW", opts)
+	rep := r.Report()
+	if rep.Match != gaps.MatchInteraction {
+		t.Errorf("Report.Match=%q", rep.Match)
+	}
+	if len(rep.Patterns) == 0 || rep.Patterns[0].LCS != "CW" {
+		t.Fatalf("patterns=%+v", rep.Patterns)
+	}
+	// C·H·E·W y H·C·D·W: entre C y W hay 2 y 1 mayúsculas
+	if got := rep.Patterns[0].Gaps; !reflect.DeepEqual(got, [][]int{{1, 2}}) {
+		t.Errorf("gaps=%v, want [[1 2]]", got)
+	}
+	if got := rep.Patterns[0].Residues1; got != "C2-x(2)-W9" {
+		t.Errorf("Residues1=%q, want C2-x(2)-W9", got)
+	}
+
+	opts.Match = ""
+	if rep := compare.Pair(context.Background(), "aCbbHdEkW", "HxxxCDqqW", opts).Report(); rep.Match != gaps.MatchDefault {
+		t.Errorf("default Report.Match=%q", rep.Match)
+	}
+}
@@ -0,0 +1,92 @@
+package lcs_test
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lucckkas/patternfinder/internal/progress"
+)
+
+func TestProgressJSONEvents(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "progress.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	rep, err := progress.New(f, progress.ModeJSON, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep.Resumed(2)
+	rep.Start()
+	rep.Begin(3, "A vs B")
+	rep.Begin(4, "A vs C")
+	if s := rep.Snapshot(); s.Done != 2 || s.Slowest == nil {
+		t.Fatalf("snapshot = %+v, want done=2 with an in-flight pair", s)
+	}
+	rep.End(3)
+	rep.End(4)
+	rep.Begin(5, "B vs C")
+	rep.End(5)
+	rep.Finish()
+
+	if _, err := f.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	var events []progress.Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		e, ok := progress.ParseEvent(scanner.Bytes())
+		if !ok {
+			t.Fatalf("línea no es un evento: %q", scanner.Text())
+		}
+		events = append(events, e)
+	}
+	if len(events) < 2 {
+		t.Fatalf("got %d events, want at least start and done", len(events))
+	}
+	first, last := events[0], events[len(events)-1]
+	if first.Event != progress.EventStart || first.Done != 2 || first.Total != 5 {
+		t.Errorf("start = %+v", first)
+	}
+	if last.Event != progress.EventDone || last.Done != 5 || last.Slowest != nil {
+		t.Errorf("done = %+v", last)
+	}
+}
+
+func TestProgressModes(t *testing.T) {
+	if err := progress.CheckMode("bogus"); err == nil {
+		t.Error("CheckMode(bogus) should fail")
+	}
+	rep, err := progress.New(os.Stderr, progress.ModeNone, 10)
+	if err != nil || rep != nil {
+		t.Fatalf("ModeNone = %v, %v; want nil reporter", rep, err)
+	}
+	// Un reporter nil no hace nada
+	rep.Start()
+	rep.Begin(1, "x")
+	rep.End(1)
+	rep.Finish()
+
+	if _, ok := progress.ParseEvent([]byte("Error al leer")); ok {
+		t.Error("ParseEvent accepted a non-event line")
+	}
+}
+
+func TestProgressEventString(t *testing.T) {
+	e := progress.Event{
+		Event:      progress.EventProgress,
+		Done:       120,
+		Total:      6000,
+		Rate:       15.3,
+		ETASeconds: 384,
+		Slowest:    &progress.InFlight{Label: "1 vs 9", ElapsedSeconds: 12},
+	}
+	want := "120/6000 (2.0%) | 15.3 pares/s | ETA 6m24s | más lento: 1 vs 9 (12s)"
+	if got := e.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
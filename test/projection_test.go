@@ -0,0 +1,35 @@
+package lcs_test
+
+import (
+	"testing"
+
+	"github.com/lucckkas/patternfinder/internal/utils"
+)
+
+func TestProjection(t *testing.T) {
+	p := utils.Project("aCbbHdeKf")
+
+	if p.Upper != "CHK" || p.Upper != utils.UpperOnly(p.Original) {
+		t.Fatalf("Upper=%q, want CHK", p.Upper)
+	}
+	wantOrig := []int{1, 4, 7}
+	for k, want := range wantOrig {
+		if got := p.OriginalPos(k); got != want {
+			t.Errorf("OriginalPos(%d)=%d, want %d", k, got, want)
+		}
+		if got := p.UpperPos(want); got != k {
+			t.Errorf("UpperPos(%d)=%d, want %d", want, got, k)
+		}
+	}
+	if got := p.UpperPos(0); got != -1 {
+		t.Errorf("UpperPos of a lowercase residue=%d, want -1", got)
+	}
+
+	if got := p.Distance(0, 2); got != 5 {
+		t.Errorf("Distance(0,2)=%d, want 5", got)
+	}
+	gapsOrig := p.Gaps([]int{0, 1, 2})
+	if len(gapsOrig) != 2 || gapsOrig[0] != 2 || gapsOrig[1] != 2 {
+		t.Errorf("Gaps=%v, want [2 2]", gapsOrig)
+	}
+}
@@ -0,0 +1,152 @@
+package lcs_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/lucckkas/patternfinder/internal/aggregate"
+	"github.com/lucckkas/patternfinder/internal/gaps"
+)
+
+func TestParsePatternRoundTrip(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"C-x(2)-H", "C-x(2)-H"},
+		{"C-x-H", "C-x(1)-H"},
+		{"<[CH]-x(2,4)-{P}-C(2)-[G>]>", "<[CH]-x(2,4)-{P}-C(2)-[G>]>"},
+		{"[DE] - x(0,3) - W.", "[DE]-x(0,3)-W"},
+		{"[>]", "[>]"},
+	}
+	for _, c := range cases {
+		pat, err := gaps.ParsePattern(c.in)
+		if err != nil {
+			t.Errorf("ParsePattern(%q): %v", c.in, err)
+			continue
+		}
+		if got := pat.String(); got != c.want {
+			t.Errorf("ParsePattern(%q).String()=%q, want %q", c.in, got, c.want)
+		}
+		again, err := gaps.ParsePattern(pat.String())
+		if err != nil || again.String() != pat.String() {
+			t.Errorf("round trip of %q gave %q (err %v)", c.in, again.String(), err)
+		}
+	}
+
+	pat, _ := gaps.ParsePattern("<[CH]-x(2,4)-{P}-C(2)-[G>]>")
+	if !pat.NTerm || !pat.CTerm || len(pat.Elements) != 5 {
+		t.Fatalf("pattern=%+v", pat)
+	}
+	if e := pat.Elements[1]; e.Kind != gaps.Any || e.Min != 2 || e.Max != 4 {
+		t.Errorf("x(2,4) parsed as %+v", e)
+	}
+	if e := pat.Elements[2]; e.Kind != gaps.Exclude || e.Residues != "P" {
+		t.Errorf("{P} parsed as %+v", e)
+	}
+	if e := pat.Elements[4]; !e.CTerm || e.Residues != "G" {
+		t.Errorf("[G>] parsed as %+v", e)
+	}
+	if n := pat.Positions(); n != 5 {
+		t.Errorf("Positions=%d, want 5", n)
+	}
+}
+
+func TestParsePatternErrors(t *testing.T) {
+	cases := []struct {
+		in  string
+		pos int // columna base 0 del error
+	}{
+		{"", 0},
+		{"C-x(2", 3},
+		{"C-[CH", 2},
+		{"C--H", 2},
+		{"C-c", 2},
+		{"C-x(3,2)", 3},
+		{"C(0)", 1},
+		{"C-<H", 2},
+		{"C>-H", 1},
+		{"C-[CC]", 4},
+		{"C-{}", 2},
+		{"CH", 1},
+		{"C. H", 3},
+	}
+	for _, c := range cases {
+		_, err := gaps.ParsePattern(c.in)
+		var se *gaps.SyntaxError
+		if !errors.As(err, &se) {
+			t.Errorf("ParsePattern(%q) err=%v, want a SyntaxError", c.in, err)
+			continue
+		}
+		if se.Pos != c.pos {
+			t.Errorf("ParsePattern(%q) error at %d, want %d: %v", c.in, se.Pos, c.pos, err)
+		}
+	}
+}
+
+func TestGapElement(t *testing.T) {
+	cases := []struct {
+		vals []int
+		want string
+		ok   bool
+	}{
+		{[]int{3}, "x(3)", true},
+		{[]int{1}, "x(1)", true},
+		{[]int{2, 3, 4}, "x(2,4)", true},
+		{[]int{0, 2}, "x(0,2)", true},
+		{[]int{0}, "", false},
+		{nil, "", false},
+	}
+	for _, c := range cases {
+		e, ok := gaps.GapElement(c.vals)
+		if ok != c.ok || (ok && e.String() != c.want) {
+			t.Errorf("GapElement(%v)=%q,%v, want %q,%v", c.vals, e.String(), ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestFormatPatternRuns(t *testing.T) {
+	if got := gaps.GapRuns([]int{0, 2, 3, 5}); !reflect.DeepEqual(got, [][]int{{0}, {2, 3}, {5}}) {
+		t.Errorf("GapRuns=%v", got)
+	}
+
+	// Valores no contiguos: un patrón por rango, nunca el rango que los contiene
+	sets := []aggregate.GapValues{{Values: []int{2, 5}}, {Values: []int{0, 1}}, {}}
+	got := aggregate.FormatPatternWithValues("CHDE", sets)
+	want := []string{"C-x(2)-H-x(0,1)-D-E", "C-x(5)-H-x(0,1)-D-E"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FormatPatternWithValues=%q, want %q", got, want)
+	}
+	sets[1].Values = []int{0, 3}
+	got = aggregate.FormatPatternWithValues("CHD", sets[:2])
+	want = []string{"C-x(2)-H-D", "C-x(2)-H-x(3)-D", "C-x(5)-H-D", "C-x(5)-H-x(3)-D"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FormatPatternWithValues=%q, want %q", got, want)
+	}
+}
+
+func TestConsolidatePatternsProsite(t *testing.T) {
+	stats := map[string]*gaps.PatternStat{
+		"C-x(2)-H-x(0)-D": {Pattern: "C-x(2)-H-x(0)-D", SequenceIndices: map[int]bool{1: true}},
+		"C-x(4)-H-D":      {Pattern: "C-x(4)-H-D", SequenceIndices: map[int]bool{2: true}},
+		"C-x(3)-H-D.":     {Pattern: "C-x(3)-H-D.", SequenceIndices: map[int]bool{3: true}},
+		"no es (patrón":   {Pattern: "no es (patrón", SequenceIndices: map[int]bool{4: true}},
+	}
+	out := gaps.ConsolidatePatterns(stats)
+	stat, ok := out["C-x(2,4)-H-D"]
+	if !ok {
+		t.Fatalf("consolidated=%v, want C-x(2,4)-H-D", keys(out))
+	}
+	if stat.UppercaseCount != 3 || len(stat.SequenceIndices) != 3 {
+		t.Errorf("UppercaseCount=%d sequences=%d, want 3 and 3", stat.UppercaseCount, len(stat.SequenceIndices))
+	}
+	if _, ok := out["no es (patrón"]; !ok || len(out) != 2 {
+		t.Errorf("invalid patterns must pass through unchanged: %v", keys(out))
+	}
+}
+
+func keys(m map[string]*gaps.PatternStat) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
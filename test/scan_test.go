@@ -0,0 +1,122 @@
+package lcs_test
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lucckkas/patternfinder/internal/gaps"
+	"github.com/lucckkas/patternfinder/internal/scan"
+)
+
+func TestScanMatches(t *testing.T) {
+	type hit struct {
+		start, end int
+		gaps       []int
+	}
+	cases := []struct {
+		pattern, seq string
+		want         []hit
+	}{
+		// Los gaps cuentan cualquier residuo; los residuos del patrón solo mayúsculas
+		{"C-x(2)-H", "aCxxHcxxH", []hit{{1, 5, []int{2}}}},
+		{"C-x(1,3)-H", "CaHbH", []hit{{0, 3, []int{1}}, {0, 5, []int{3}}}},
+		{"C-x(0,1)-H", "CHCaH", []hit{{0, 2, []int{0}}, {2, 5, []int{1}}}},
+		{"[CH]-x(2)-{P}", "CaaPHaaQ", []hit{{4, 8, []int{2}}}},
+		{"C(2)-x-H", "CCCaH", []hit{{1, 5, []int{1}}}},
+		{"<C-x(1)-H", "CaHCaH", []hit{{0, 3, []int{1}}}},
+		{"C-x(1)-H>", "CaHCaH", []hit{{3, 6, []int{1}}}},
+		{"C-x(1)-[H>]", "CaHCa", []hit{{0, 3, []int{1}}, {3, 5, []int{1}}}},
+		{"C-x(2)-H", "cxxH", nil},
+	}
+	for _, c := range cases {
+		m, err := scan.Compile(c.pattern)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", c.pattern, err)
+		}
+		var got []hit
+		for _, mt := range must(m.FindAll(c.seq, 0)) {
+			if mt.Residues != c.seq[mt.Start:mt.End] {
+				t.Errorf("%s in %s: Residues=%q at %d:%d", c.pattern, c.seq, mt.Residues, mt.Start, mt.End)
+			}
+			got = append(got, hit{mt.Start, mt.End, mt.Gaps})
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s in %s: matches=%v, want %v", c.pattern, c.seq, got, c.want)
+		}
+		if m.Matches(c.seq) != (len(c.want) > 0) {
+			t.Errorf("%s in %s: Matches=%v", c.pattern, c.seq, m.Matches(c.seq))
+		}
+	}
+}
+
+func must(matches []scan.Match, _ bool) []scan.Match { return matches }
+
+func TestScanLimitAndSupport(t *testing.T) {
+	m, err := scan.Compile("C-x(0,10)-H")
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches, truncated := m.FindAll("CCCCHHHH", 5)
+	if len(matches) != 5 || !truncated {
+		t.Errorf("FindAll limit 5: %d matches, truncated=%v", len(matches), truncated)
+	}
+	if all, truncated := m.FindAll("CCCCHHHH", 0); len(all) != 16 || truncated {
+		t.Errorf("FindAll: %d matches, want 16", len(all))
+	}
+	if n := m.Support([]string{"CaH", "cah", "HC", "xCxxxH"}); n != 2 {
+		t.Errorf("Support=%d, want 2", n)
+	}
+
+	// Sin coincidencias y con muchos x variables no debe explotar
+	slow, _ := scan.Compile("x(0,30)-x(0,30)-x(0,30)-x(0,30)-Q")
+	if slow.Matches(strings.Repeat("aC", 5000)) {
+		t.Errorf("unexpected match")
+	}
+}
+
+// Los gaps de las coincidencias deben ser los mismos que calcula el motor de
+// gaps para un patrón de residuos sueltos.
+func TestScanAgreesWithGapEngine(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	alphabet := "CHDWcdhwxy"
+	for iter := 0; iter < 200; iter++ {
+		seq := make([]byte, 8+rng.Intn(20))
+		for i := range seq {
+			seq[i] = alphabet[rng.Intn(len(alphabet))]
+		}
+		letters := []byte("CHDW")
+		pattern := make([]byte, 2+rng.Intn(2))
+		for i := range pattern {
+			pattern[i] = letters[rng.Intn(len(letters))]
+		}
+
+		parts := make([]string, len(pattern))
+		for i, c := range pattern {
+			parts[i] = string(c)
+		}
+		m, err := scan.Compile(strings.Join(parts, "-x(0,100)-"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sets, ok := gaps.AllGapValuesDistanceTotalViable(string(seq), string(pattern))
+		got := make([]map[int]struct{}, len(pattern)-1)
+		for i := range got {
+			got[i] = make(map[int]struct{})
+		}
+		matches, _ := m.FindAll(string(seq), 0)
+		for _, mt := range matches {
+			for i, g := range mt.Gaps {
+				got[i][g] = struct{}{}
+			}
+		}
+		if ok != (len(matches) > 0) {
+			t.Fatalf("%s in %s: gap engine ok=%v, scanner found %d", pattern, seq, ok, len(matches))
+		}
+		if ok && !reflect.DeepEqual(got, sets) {
+			t.Fatalf("%s in %s: scanner gaps %v, gap engine %v", pattern, seq, got, sets)
+		}
+	}
+}
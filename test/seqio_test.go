@@ -0,0 +1,110 @@
+package lcs_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lucckkas/patternfinder/internal/seqio"
+)
+
+func TestReadPlain(t *testing.T) {
+	recs, format, err := seqio.Read(strings.NewReader("# comentario\nAxxB\n\n  CyyD  \n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != seqio.FormatPlain {
+		t.Errorf("format=%v, want plain", format)
+	}
+	want := []seqio.Record{{ID: "1", Seq: "AxxB"}, {ID: "2", Seq: "CyyD"}}
+	if !reflect.DeepEqual(recs, want) {
+		t.Errorf("got %+v, want %+v", recs, want)
+	}
+}
+
+func TestReadFASTA(t *testing.T) {
+	in := ">1ABC_A zinc finger\naCxx\nHyy DzW\n; comentario\n\n>2XYZ\nCaaH\n"
+	recs, format, err := seqio.Read(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != seqio.FormatFASTA {
+		t.Errorf("format=%v, want FASTA", format)
+	}
+	want := []seqio.Record{
+		{ID: "1ABC_A", Description: "zinc finger", Seq: "aCxxHyyDzW"},
+		{ID: "2XYZ", Seq: "CaaH"},
+	}
+	if !reflect.DeepEqual(recs, want) {
+		t.Errorf("got %+v, want %+v", recs, want)
+	}
+	if got := seqio.Seqs(recs); !reflect.DeepEqual(got, []string{"aCxxHyyDzW", "CaaH"}) {
+		t.Errorf("Seqs=%v", got)
+	}
+}
+
+func TestReadFASTAErrors(t *testing.T) {
+	for _, bad := range []string{">A\n>B\nCC\n", ">\nCC\n", ">A\nCC\n>B\n"} {
+		if _, _, err := seqio.Read(strings.NewReader(bad)); err == nil {
+			t.Errorf("Read(%q) should fail", bad)
+		}
+	}
+}
+
+func TestReadSegments(t *testing.T) {
+	in := `{
+  "1tf3": {"ZN_A_2": ["CxxCH"], "DT_E_1": ["R"]},
+  "1a1f": {"ZN_B_202": ["CriC", ""], "ZN_A_201": ["CpvC", "HiriH"], "DC_B_11": []}
+}`
+	recs, format, err := seqio.Read(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != seqio.FormatSegments {
+		t.Fatalf("format=%v, want segments", format)
+	}
+	var ids []string
+	for _, r := range recs {
+		ids = append(ids, r.ID)
+	}
+	want := []string{"1a1f/ZN_A_201/1", "1a1f/ZN_A_201/2", "1a1f/ZN_B_202/1", "1tf3/DT_E_1/1", "1tf3/ZN_A_2/1"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Fatalf("ids=%v, want %v", ids, want)
+	}
+	if r := recs[1]; r.Protein != "1a1f" || r.Ligand != "ZN_A_201" || r.Segment != 2 || r.Seq != "HiriH" {
+		t.Errorf("metadata=%+v", r)
+	}
+
+	f := seqio.Filter{Ligand: "zn", Proteins: []string{"1A1F"}, Chains: []string{"a"}}
+	got := f.Apply(recs)
+	if len(got) != 2 || got[0].ID != "1a1f/ZN_A_201/1" {
+		t.Errorf("filtered=%+v", got)
+	}
+	if got := (seqio.Filter{Ligand: "ZN"}).Apply(recs); len(got) != 4 {
+		t.Errorf("ligand filter kept %d records, want 4", len(got))
+	}
+
+	if _, _, err := seqio.Read(strings.NewReader(`{"1a1f": ["x"]}`)); err == nil {
+		t.Errorf("invalid segments JSON should fail")
+	}
+}
+
+func TestDedup(t *testing.T) {
+	seqs := []string{"aCxxH", "KDE", "aCxxH", "bCyH", "KDE"}
+
+	exact := seqio.Dedup(seqs, seqio.DedupExact)
+	want := []seqio.Group{{Rep: 0, Members: []int{0, 2}}, {Rep: 1, Members: []int{1, 4}}, {Rep: 3, Members: []int{3}}}
+	if !reflect.DeepEqual(exact, want) {
+		t.Errorf("exact = %v, want %v", exact, want)
+	}
+
+	upper := seqio.Dedup(seqs, seqio.DedupUpper)
+	want = []seqio.Group{{Rep: 0, Members: []int{0, 2, 3}}, {Rep: 1, Members: []int{1, 4}}}
+	if !reflect.DeepEqual(upper, want) {
+		t.Errorf("upper = %v, want %v", upper, want)
+	}
+
+	if none := seqio.Dedup(seqs, seqio.DedupNone); len(none) != len(seqs) {
+		t.Errorf("none: got %d groups, want %d", len(none), len(seqs))
+	}
+}
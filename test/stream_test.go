@@ -0,0 +1,76 @@
+package lcs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lucckkas/patternfinder/internal/lcs"
+)
+
+func TestEnumeratorMatchesBacktracking(t *testing.T) {
+	sec1 := generateRandomSequence(60, 31)
+	sec2 := generateRandomSequence(55, 32)
+	dp := lcs.DPTable(sec1, sec2)
+
+	want := make(map[string]bool)
+	for _, s := range lcs.Backtracking(sec1, sec2, dp) {
+		want[s] = true
+	}
+
+	enum := lcs.NewEnumerator(sec1, sec2, dp, lcs.Limits{})
+	got, err := enum.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d LCS, want %d", len(got), len(want))
+	}
+	for _, s := range got {
+		if !want[s] {
+			t.Errorf("LCS %q not found by Backtracking", s)
+		}
+	}
+
+	// Un límite igual al total no debe marcar truncado
+	enum = lcs.NewEnumerator(sec1, sec2, dp, lcs.Limits{MaxCount: len(want)})
+	if _, err := enum.Collect(context.Background()); err != nil {
+		t.Errorf("MaxCount equal to total: unexpected error %v", err)
+	}
+}
+
+func TestEnumeratorLimits(t *testing.T) {
+	sec1, sec2 := "ABCDEFGH", "HGFEDCBA" // 8 LCS de largo 1
+	dp := lcs.DPTable(sec1, sec2)
+
+	enum := lcs.NewEnumerator(sec1, sec2, dp, lcs.Limits{MaxCount: 3})
+	got, err := enum.Collect(context.Background())
+	if !errors.Is(err, lcs.ErrMaxCount) || !enum.Truncated() {
+		t.Errorf("err=%v, want ErrMaxCount", err)
+	}
+	if len(got) != 3 {
+		t.Errorf("got %d LCS, want 3", len(got))
+	}
+
+	// Cortar el range desde el consumidor no es un truncado por límite
+	enum = lcs.NewEnumerator(sec1, sec2, dp, lcs.Limits{})
+	for range enum.All(context.Background()) {
+		break
+	}
+	if enum.Truncated() {
+		t.Errorf("breaking out of the loop must not report truncation")
+	}
+}
+
+func TestEnumeratorContext(t *testing.T) {
+	sec1 := generateRandomSequence(200, 41)
+	sec2 := generateRandomSequence(200, 42)
+	dp := lcs.DPTable(sec1, sec2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	enum := lcs.NewEnumerator(sec1, sec2, dp, lcs.Limits{})
+	if _, err := enum.Collect(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("err=%v, want context.Canceled", err)
+	}
+}